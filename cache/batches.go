@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A batch records the files it has finished in its own file under batches/,
+// one JSON CompletedFile per line, appended as each finishes. A batch
+// interrupted part way through can then resume with the files it hasn't
+// finished.
+
+// CompletedFile is a file a batch finished, as Drive listed it then
+type CompletedFile struct {
+	ID           string    `json:"id"`
+	ModifiedTime time.Time `json:"modified_time"`
+	Size         int64     `json:"size"`
+}
+
+// batchFile is where the batch with the given key records its finished files
+func (m *Manager) batchFile(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(m.cacheDir, "batches", hex.EncodeToString(sum[:8]))
+}
+
+// CompletedFiles returns the files an earlier run of the batch finished, by
+// ID. A batch with no record has finished none.
+func (m *Manager) CompletedFiles(key string) (map[string]CompletedFile, error) {
+	f, err := os.Open(m.batchFile(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]CompletedFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	done := make(map[string]CompletedFile)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// A line cut short by a crash is just a file to download again
+		var cf CompletedFile
+		if err := json.Unmarshal(scanner.Bytes(), &cf); err == nil && cf.ID != "" {
+			done[cf.ID] = cf
+		}
+	}
+	return done, scanner.Err()
+}
+
+// BatchState appends the files a batch finishes to its record. It is safe
+// for concurrent use, and a nil BatchState records nothing.
+type BatchState struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenBatchState opens the record of the batch with the given key, keeping
+// the files already in it.
+func (m *Manager) OpenBatchState(key string) (*BatchState, error) {
+	path := m.batchFile(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchState{f: f}, nil
+}
+
+// Complete records that cf is finished. Each file is written straight to
+// the record, so a crash loses at most the last one.
+func (s *BatchState) Complete(cf CompletedFile) error {
+	if s == nil {
+		return nil
+	}
+	line, err := json.Marshal(cf)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the record
+func (s *BatchState) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.f.Close()
+}
+
+// ForgetBatch removes the record of the batch with the given key, once
+// there is nothing left to resume
+func (m *Manager) ForgetBatch(key string) error {
+	err := os.Remove(m.batchFile(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBatchState(t *testing.T) {
+	m := &Manager{cacheDir: t.TempDir()}
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := CompletedFile{ID: "a", ModifiedTime: modified, Size: 5}
+	b := CompletedFile{ID: "b", ModifiedTime: modified, Size: 7}
+
+	// Reopening a record keeps what is already in it
+	for _, cf := range []CompletedFile{a, b} {
+		state, err := m.OpenBatchState("batch")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.Complete(cf); err != nil {
+			t.Fatal(err)
+		}
+		state.Close()
+	}
+
+	// A line cut short by a crash is ignored
+	f, err := os.OpenFile(m.batchFile("batch"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"c","modi`)
+	f.Close()
+
+	done, err := m.CompletedFiles("batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]CompletedFile{"a": a, "b": b}; !reflect.DeepEqual(done, want) {
+		t.Errorf("CompletedFiles() = %v, want %v", done, want)
+	}
+
+	// Other batches have their own records
+	if other, err := m.CompletedFiles("other"); err != nil || len(other) != 0 {
+		t.Errorf("CompletedFiles() of another batch = %v, %v, want none", other, err)
+	}
+	if err := m.ForgetBatch("other"); err != nil {
+		t.Errorf("ForgetBatch() of a batch with no record = %v", err)
+	}
+	if err := m.ForgetBatch("batch"); err != nil {
+		t.Fatal(err)
+	}
+	if done, _ := m.CompletedFiles("batch"); len(done) != 0 {
+		t.Errorf("CompletedFiles() after ForgetBatch = %v, want none", done)
+	}
+}
@@ -2,23 +2,37 @@ package cache
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 )
 
+// DefaultTTL is how long a cached folder listing is considered fresh.
+const DefaultTTL = 7 * 24 * time.Hour
+
+// SchemaVersion is the current on-disk cache format version. Bump it whenever
+// CachedFile, FolderCache, or Cache change shape; caches written with any other
+// version are discarded on load rather than deserialized with zero values.
+const SchemaVersion = 4
+
 // CachedFile represents a cached file entry with its metadata.
 // This mirrors the drive.DriveFile structure for serialization.
 type CachedFile struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Path         string    `json:"path"`
-	Size         int64     `json:"size"`
-	FolderID     string    `json:"folder_id"`
-	MimeType     string    `json:"mime_type"`
-	CreatedTime  time.Time `json:"created_time"`
-	ModifiedTime time.Time `json:"modified_time"`
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Path           string    `json:"path"`
+	Size           int64     `json:"size"`
+	MD5Checksum    string    `json:"md5_checksum,omitempty"`
+	FolderID       string    `json:"folder_id"`
+	RootFolderID   string    `json:"root_folder_id"`
+	RootFolderName string    `json:"root_folder_name"`
+	MimeType       string    `json:"mime_type"`
+	Owner          string    `json:"owner,omitempty"`
+	OwnerEmail     string    `json:"owner_email,omitempty"`
+	CreatedTime    time.Time `json:"created_time"`
+	ModifiedTime   time.Time `json:"modified_time"`
 }
 
 // FolderCache represents cached data for a single Google Drive folder.
@@ -35,6 +49,8 @@ type FolderCache struct {
 
 // Cache represents the full cache structure stored on disk.
 type Cache struct {
+	// Version is the schema version the cache was written with
+	Version int `json:"version"`
 	// Folders maps folder IDs to their cached data
 	Folders map[string]*FolderCache `json:"folders"`
 }
@@ -58,22 +74,26 @@ func NewManager() (*Manager, error) {
 	m := &Manager{
 		cacheDir:  cacheDir,
 		cacheFile: filepath.Join(cacheDir, "folders.json"),
-		cache: &Cache{
-			Folders: make(map[string]*FolderCache),
-		},
+		cache:     newCache(),
 	}
 
 	// Load existing cache
 	if err := m.load(); err != nil {
-		// If cache doesn't exist or is corrupted, start fresh
-		m.cache = &Cache{
-			Folders: make(map[string]*FolderCache),
-		}
+		// If cache doesn't exist, is corrupted, or has an old schema, start fresh
+		m.cache = newCache()
 	}
 
 	return m, nil
 }
 
+// newCache returns an empty cache at the current schema version
+func newCache() *Cache {
+	return &Cache{
+		Version: SchemaVersion,
+		Folders: make(map[string]*FolderCache),
+	}
+}
+
 // getCacheDir returns the cache directory path
 func getCacheDir() (string, error) {
 	// Try XDG_CACHE_HOME first
@@ -97,7 +117,19 @@ func (m *Manager) load() error {
 		return err
 	}
 
-	return json.Unmarshal(data, m.cache)
+	var c Cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	if c.Version != SchemaVersion {
+		return fmt.Errorf("cache schema version %d does not match %d", c.Version, SchemaVersion)
+	}
+	if c.Folders == nil {
+		c.Folders = make(map[string]*FolderCache)
+	}
+
+	m.cache = &c
+	return nil
 }
 
 // save writes the cache to disk
@@ -159,8 +191,64 @@ func (m *Manager) GetAllCachedFolderIDs() []string {
 func (m *Manager) Clear() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.cache = &Cache{
-		Folders: make(map[string]*FolderCache),
-	}
+	m.cache = newCache()
 	return m.save()
 }
+
+// Stats summarizes the contents of the cache.
+type Stats struct {
+	// Folders is the number of cached folder entries
+	Folders int
+	// Files is the total number of files across all entries
+	Files int
+	// TotalBytes is the aggregate size of all cached files
+	TotalBytes int64
+	// OldestFetch is the FetchedAt of the oldest entry (zero if empty)
+	OldestFetch time.Time
+	// NewestFetch is the FetchedAt of the newest entry (zero if empty)
+	NewestFetch time.Time
+}
+
+// Stats returns aggregate statistics about the cached folders
+func (m *Manager) Stats() Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var st Stats
+	for _, fc := range m.cache.Folders {
+		st.Folders++
+		st.Files += len(fc.Files)
+		for _, f := range fc.Files {
+			st.TotalBytes += f.Size
+		}
+		if st.OldestFetch.IsZero() || fc.FetchedAt.Before(st.OldestFetch) {
+			st.OldestFetch = fc.FetchedAt
+		}
+		if fc.FetchedAt.After(st.NewestFetch) {
+			st.NewestFetch = fc.FetchedAt
+		}
+	}
+	return st
+}
+
+// Path returns the location of the cache file on disk
+func (m *Manager) Path() string {
+	return m.cacheFile
+}
+
+// Prune removes folders fetched more than maxAge ago and returns how many were removed
+func (m *Manager) Prune(maxAge time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for id, fc := range m.cache.Folders {
+		if fc.FetchedAt.Before(cutoff) {
+			delete(m.cache.Folders, id)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, m.save()
+}
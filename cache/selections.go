@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Selection is a saved set of selected files for one set of folders.
+type Selection struct {
+	// FileIDs are the Drive IDs of the selected files
+	FileIDs []string `json:"file_ids"`
+	// SavedAt is when the selection was saved
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// selectionsFile is where selections are kept, next to the folder listings
+// but in their own file so clearing the listing cache doesn't lose them
+func (m *Manager) selectionsFile() string {
+	return filepath.Join(m.cacheDir, "selections.json")
+}
+
+// loadSelections reads every saved selection, keyed by folder key. A missing
+// file is an empty set.
+func (m *Manager) loadSelections() (map[string]*Selection, error) {
+	selections := make(map[string]*Selection)
+	data, err := os.ReadFile(m.selectionsFile())
+	if errors.Is(err, fs.ErrNotExist) {
+		return selections, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &selections); err != nil {
+		return nil, err
+	}
+	return selections, nil
+}
+
+// GetSelection returns the selection saved for a folder key, or nil if there is none
+func (m *Manager) GetSelection(key string) *Selection {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	selections, err := m.loadSelections()
+	if err != nil {
+		return nil
+	}
+	return selections[key]
+}
+
+// SaveSelection stores the selected file IDs for a folder key, replacing any
+// earlier selection. An empty list removes the saved selection.
+func (m *Manager) SaveSelection(key string, fileIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	selections, err := m.loadSelections()
+	if err != nil {
+		// An unreadable file would otherwise block saving forever
+		selections = make(map[string]*Selection)
+	}
+
+	if len(fileIDs) == 0 {
+		delete(selections, key)
+	} else {
+		selections[key] = &Selection{FileIDs: fileIDs, SavedAt: time.Now()}
+	}
+
+	if err := os.MkdirAll(m.cacheDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(selections, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.selectionsFile(), data, 0o644)
+}
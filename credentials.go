@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+)
+
+// credentialsFileKey is the entry of the credentials file holding the API key
+const credentialsFileKey = "api_key"
+
+// credentialsFilePath returns where a stored API key is looked up:
+// $XDG_CONFIG_HOME/gdrive-dl/credentials, or ~/.config/gdrive-dl/credentials
+func credentialsFilePath() (string, error) {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "gdrive-dl", "credentials"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "gdrive-dl", "credentials"), nil
+}
+
+// apiKeyFromCredentialsFile returns the api_key entry of the credentials
+// file, a key=value file like .env, or "" if there is no such file or entry
+func apiKeyFromCredentialsFile() (string, error) {
+	path, err := credentialsFilePath()
+	if err != nil {
+		return "", err
+	}
+	values, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	return values[credentialsFileKey], nil
+}
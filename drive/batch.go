@@ -0,0 +1,138 @@
+package drive
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchProgressInterval is how often DownloadFiles calls the BatchHandler
+// while the batch is changing.
+const BatchProgressInterval = 250 * time.Millisecond
+
+// BatchProgress is the overall progress of a batch of downloads.
+type BatchProgress struct {
+	// FilesTotal is the number of files in the batch
+	FilesTotal int
+	// FilesDone counts finished files, whatever the outcome; FilesFailed,
+	// FilesSkipped and FilesCancelled break some of them down
+	FilesDone      int
+	FilesFailed    int
+	FilesSkipped   int
+	FilesCancelled int
+	// TotalBytes is the sum of the sizes Drive reported, so files of unknown
+	// size add nothing to it
+	TotalBytes int64
+	// LoadedBytes is the sum of every file's bytes downloaded so far
+	LoadedBytes int64
+}
+
+// Percent returns LoadedBytes as a percentage of TotalBytes, or 0 if the total is unknown.
+func (p BatchProgress) Percent() float64 {
+	if p.TotalBytes <= 0 {
+		return 0
+	}
+	return float64(p.LoadedBytes) / float64(p.TotalBytes) * 100
+}
+
+// BatchHandler receives the progress of a whole DownloadFiles batch.
+type BatchHandler func(BatchProgress)
+
+// SetBatchHandler registers h to receive the overall progress of each
+// DownloadFiles call, every BatchProgressInterval while it changes and once
+// more when the batch ends. nil removes it.
+func (c *Client) SetBatchHandler(h BatchHandler) {
+	c.batch = h
+}
+
+// BatchTracker sums per-file DownloadProgress updates into a BatchProgress.
+// It is safe for concurrent use.
+type BatchTracker struct {
+	mu     sync.Mutex
+	sizes  map[string]int64
+	loaded map[string]int64
+	done   map[string]bool
+	p      BatchProgress
+}
+
+// NewBatchTracker returns a tracker for a batch of files with nothing downloaded yet.
+func NewBatchTracker(files []DriveFile) *BatchTracker {
+	t := &BatchTracker{
+		sizes:  make(map[string]int64, len(files)),
+		loaded: make(map[string]int64),
+		done:   make(map[string]bool),
+	}
+	for _, f := range files {
+		if _, dup := t.sizes[f.ID]; dup {
+			continue
+		}
+		t.sizes[f.ID] = f.Size
+		t.p.FilesTotal++
+		t.p.TotalBytes += f.Size
+	}
+	return t
+}
+
+// Update records p. Progress for files outside the batch, for a file that
+// already finished, or reporting a retry is ignored.
+func (t *BatchTracker) Update(p DownloadProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.sizes[p.FileID]; !ok || t.done[p.FileID] || p.Retry > 0 {
+		return
+	}
+	t.p.LoadedBytes += p.BytesLoaded - t.loaded[p.FileID]
+	t.loaded[p.FileID] = p.BytesLoaded
+	if !p.Done {
+		return
+	}
+
+	t.done[p.FileID] = true
+	t.p.FilesDone++
+	switch {
+	case p.Error != nil:
+		t.p.FilesFailed++
+	case p.Cancelled:
+		t.p.FilesCancelled++
+	case p.Skipped:
+		t.p.FilesSkipped++
+	}
+}
+
+// Progress returns the batch's progress so far.
+func (t *BatchTracker) Progress() BatchProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.p
+}
+
+// reportBatch feeds the progress arriving on in into tracker, passes it on
+// to out if set, and calls the batch handler every BatchProgressInterval
+// while anything changed. It makes a last call and closes done once in is
+// closed.
+func (c *Client) reportBatch(tracker *BatchTracker, in <-chan DownloadProgress, out chan<- DownloadProgress, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(BatchProgressInterval)
+	defer ticker.Stop()
+
+	changed := true
+	for {
+		select {
+		case p, ok := <-in:
+			if !ok {
+				c.batch(tracker.Progress())
+				return
+			}
+			tracker.Update(p)
+			changed = true
+			if out != nil {
+				out <- p
+			}
+		case <-ticker.C:
+			if changed {
+				c.batch(tracker.Progress())
+				changed = false
+			}
+		}
+	}
+}
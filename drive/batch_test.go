@@ -0,0 +1,33 @@
+package drive
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatchTracker(t *testing.T) {
+	tracker := NewBatchTracker([]DriveFile{
+		{ID: "a", Size: 100},
+		{ID: "b", Size: 300},
+		{ID: "c", Size: 0},
+		{ID: "a", Size: 100},
+	})
+
+	tracker.Update(DownloadProgress{FileID: "a", BytesLoaded: 40})
+	tracker.Update(DownloadProgress{FileID: "a", BytesLoaded: 100, Done: true})
+	tracker.Update(DownloadProgress{FileID: "a", BytesLoaded: 0, Done: true, Error: errors.New("late")})
+	tracker.Update(DownloadProgress{FileID: "b", BytesLoaded: 300, Done: true, Skipped: true})
+	tracker.Update(DownloadProgress{FileID: "c", BytesLoaded: 10})
+	tracker.Update(DownloadProgress{FileID: "elsewhere", BytesLoaded: 999, Done: true})
+
+	want := BatchProgress{FilesTotal: 3, FilesDone: 2, FilesSkipped: 1, TotalBytes: 400, LoadedBytes: 410}
+	if got := tracker.Progress(); got != want {
+		t.Errorf("Progress() = %+v, want %+v", got, want)
+	}
+
+	tracker.Update(DownloadProgress{FileID: "c", Done: true, Error: errors.New("boom")})
+	got := tracker.Progress()
+	if got.FilesDone != 3 || got.FilesFailed != 1 || got.LoadedBytes != 400 || got.Percent() != 100 {
+		t.Errorf("Progress() after failure = %+v, want all done, one failed, 400 bytes", got)
+	}
+}
@@ -1,55 +1,105 @@
 package drive
 
 import (
+	"cmp"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"iter"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
-	"google.golang.org/api/option"
+	"google.golang.org/api/googleapi/transport"
 )
 
 // Constants for configuration
 const (
 	// DefaultPageSize is the number of files to fetch per API request
 	DefaultPageSize = 1000
+	// MaxPageSize is the largest page size the Drive API accepts
+	MaxPageSize = 1000
 	// DefaultMaxDepth is the maximum recursion depth for folder traversal
 	DefaultMaxDepth = 10
+	// DefaultMaxConcurrent is the default number of parallel listings or downloads
+	DefaultMaxConcurrent = 4
+	// PartSuffix is appended to a file's name while it is being downloaded.
+	// The file is renamed to its final name only once the download completes.
+	PartSuffix = ".part"
 	// OAuthTimeout is the maximum time to wait for OAuth authorization
 	OAuthTimeout = 5 * time.Minute
+	// CredentialsEnv holds the OAuth credentials JSON when the credentials
+	// file is missing, e.g. in a container
+	CredentialsEnv = "GOOGLE_CREDENTIALS"
+	// TokenEnv holds a saved token.json when the token file is missing
+	TokenEnv = "GOOGLE_TOKEN"
 )
 
 // Pre-compiled regex for extracting folder IDs from URLs
 var folderIDRegex = regexp.MustCompile(`/folders/([a-zA-Z0-9_-]+)`)
 
-// DriveFile represents a file from Google Drive with its metadata.
+// fileIDRegex matches a file link such as https://drive.google.com/file/d/ID/view
+var fileIDRegex = regexp.MustCompile(`/file/d/([a-zA-Z0-9_-]+)`)
+
+// bareIDRegex matches an ID given on its own rather than in a link
+var bareIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{10,}$`)
+
+// openIDRegex matches the legacy https://drive.google.com/open?id=ID form
+var openIDRegex = regexp.MustCompile(`/open\?(?:.*&)?id=([a-zA-Z0-9_-]+)`)
+
+// DriveFile represents a file from Google Drive with its metadata. Its JSON
+// form uses the same field names as cache.CachedFile.
 type DriveFile struct {
 	// ID is the unique Google Drive file identifier
-	ID string
+	ID string `json:"id"`
 	// Name is the file name
-	Name string
-	// Path is the parent folder path for nested folders
-	Path string
-	// Size is the file size in bytes
-	Size int64
+	Name string `json:"name"`
+	// Path is the parent folder path for nested folders. It always uses "/"
+	// separators; use LocalPath to turn it into a filesystem path.
+	Path string `json:"path"`
+	// Size is the file size in bytes. Drive omits it for some shared and
+	// Google-native files, in which case it is 0 and the real size is unknown.
+	Size int64 `json:"size"`
+	// MD5Checksum is the hex MD5 of the content, if Drive reports one
+	MD5Checksum string `json:"md5_checksum,omitempty"`
 	// FolderID is the ID of the parent folder
-	FolderID string
+	FolderID string `json:"folder_id"`
+	// RootFolderID is the ID of the top-level folder link the file was found under
+	RootFolderID string `json:"root_folder_id"`
+	// RootFolderName is the name of the top-level folder link (empty if unknown)
+	RootFolderName string `json:"root_folder_name"`
 	// MimeType is the file's MIME type
-	MimeType string
+	MimeType string `json:"mime_type"`
+	// Owner is the display name of the file's first owner (empty if Drive didn't say,
+	// which is common with API-key auth)
+	Owner string `json:"owner,omitempty"`
+	// OwnerEmail is the email address of the file's first owner, if known
+	OwnerEmail string `json:"owner_email,omitempty"`
 	// CreatedTime is when the file was created
-	CreatedTime time.Time
+	CreatedTime time.Time `json:"created_time"`
 	// ModifiedTime is when the file was last modified
-	ModifiedTime time.Time
+	ModifiedTime time.Time `json:"modified_time"`
+	// RevisionID, if set, picks an earlier version to download instead of
+	// the current one (see AtRevision)
+	RevisionID string `json:"revision_id,omitempty"`
 }
 
 // DisplayName returns the name with path prefix if available
@@ -60,84 +110,346 @@ func (f DriveFile) DisplayName() string {
 	return f.Name
 }
 
+// String returns a short summary of the file for logs: its display name, size, and ID
+func (f DriveFile) String() string {
+	size := "size unknown"
+	if f.Size > 0 {
+		size = fmt.Sprintf("%d bytes", f.Size)
+	}
+	return fmt.Sprintf("%s (%s, %s)", f.DisplayName(), size, f.ID)
+}
+
+// WebLink returns the browser URL for the file
+func (f DriveFile) WebLink() string {
+	return "https://drive.google.com/file/d/" + f.ID + "/view"
+}
+
+// FormatSize formats a byte count with a binary unit, e.g. "1.5 MB"
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
 // DownloadProgress tracks the progress of a file download.
 type DownloadProgress struct {
 	// FileID is the Google Drive file identifier
 	FileID string
 	// FileName is the display name of the file being downloaded
 	FileName string
-	// BytesLoaded is the number of bytes downloaded so far
+	// BytesLoaded is the number of bytes downloaded so far. Updates while
+	// downloading are throttled (see progressInterval); the final one is exact.
 	BytesLoaded int64
-	// TotalBytes is the total file size in bytes
+	// TotalBytes is the total file size in bytes. It is 0 when Drive did not
+	// report a size, so callers should show indeterminate progress rather than
+	// a percentage.
 	TotalBytes int64
 	// Done indicates whether the download is complete
 	Done bool
 	// Skipped indicates whether the file was skipped (already exists locally)
 	Skipped bool
+	// Cancelled indicates the download was stopped before it finished
+	Cancelled bool
+	// LocalPath is where the file was saved, or the existing copy that was
+	// kept. It is set on the final progress sent by DownloadFile.
+	LocalPath string
 	// Error contains any error that occurred during download
 	Error error
+	// Retry is set while the download waits to retry a failed request: it
+	// counts retries of that request so far, out of MaxRetries, and
+	// RetryDelay is the wait before the next attempt. Progress sent with
+	// Retry set doesn't report BytesLoaded; the next update does.
+	Retry      int
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// ExistPolicy controls what DownloadFile does when the destination file already exists.
+type ExistPolicy int
+
+const (
+	// ExistSkip skips the download if the local file has the same size.
+	ExistSkip ExistPolicy = iota
+	// ExistOverwrite always downloads and replaces the local file.
+	ExistOverwrite
+	// ExistRename downloads to a numbered copy, e.g. "name (1).ext".
+	ExistRename
+	// ExistNewer downloads only if the remote file was modified after the local one.
+	ExistNewer
+)
+
+// ParseExistPolicy parses a policy name: skip, overwrite, rename, or newer.
+func ParseExistPolicy(s string) (ExistPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "skip":
+		return ExistSkip, nil
+	case "overwrite":
+		return ExistOverwrite, nil
+	case "rename":
+		return ExistRename, nil
+	case "newer":
+		return ExistNewer, nil
+	}
+	return ExistSkip, fmt.Errorf("unknown exist policy %q (valid: skip, overwrite, rename, newer)", s)
+}
+
+// String returns the policy name as accepted by ParseExistPolicy.
+func (p ExistPolicy) String() string {
+	switch p {
+	case ExistOverwrite:
+		return "overwrite"
+	case ExistRename:
+		return "rename"
+	case ExistNewer:
+		return "newer"
+	}
+	return "skip"
 }
 
 // Client wraps the Google Drive API and provides methods for listing and downloading files.
 type Client struct {
-	service *drive.Service
+	service     filesAPI
+	existPolicy ExistPolicy
+	pageSize    int64
+	maxDepth    int
+	retries     int
+	limiter     *rateLimiter
+
+	// retryBaseDelay is the first retry's backoff (0 = DefaultRetryBaseDelay)
+	retryBaseDelay time.Duration
+
+	// Segmented downloads (disabled when segments <= 1)
+	segments         int
+	segmentThreshold int64
+
+	// exports picks the format of exported Workspace files (nil = DefaultExportMap)
+	exports ExportMap
+	// names renames downloaded files (nil = keep Drive's names)
+	names *template.Template
+	// structure arranges downloads under the output directory ("" = mirror)
+	structure OutputStructure
+	// flattenDelim, if set, joins folders into file names instead (see SetFlattenNames)
+	flattenDelim string
+
+	// dedupeContent also collapses files with the same size and MD5 across links
+	dedupeContent bool
+	// duplicates is how many files the last multi-folder listing collapsed
+	duplicates atomic.Int64
+	// apiCalls counts requests sent to the API (see APICalls)
+	apiCalls atomic.Int64
+
+	// onQuotaError is called for every rate-limit response, even retried ones
+	onQuotaError func()
+	// confirmOverwrite, if set, is asked before ExistOverwrite replaces a changed file
+	confirmOverwrite OverwriteConfirmFunc
+	// recheckSize re-fetches a file's size before replacing a local copy of another size
+	recheckSize bool
+	// events receives download events for embedders (see SetEventHandler)
+	events EventHandler
+	// batch receives the overall progress of DownloadFiles (see SetBatchHandler)
+	batch BatchHandler
+	// apiKey is set when the client authenticates with an API key only
+	apiKey bool
+}
+
+// SetExistPolicy sets how DownloadFile treats files that already exist locally.
+func (c *Client) SetExistPolicy(p ExistPolicy) {
+	c.existPolicy = p
+}
+
+// OverwriteConfirmFunc decides whether DownloadFile may replace the local
+// copy of file at localPath. It may block, e.g. to ask the user, and should
+// return false once ctx is done.
+type OverwriteConfirmFunc func(ctx context.Context, file DriveFile, localPath string) bool
+
+// SetOverwriteConfirm makes ExistOverwrite ask fn before replacing a local
+// copy whose size differs from Drive's, which suggests it was edited
+// locally; a file fn declines is skipped. Copies of the same size, or of a
+// file whose size Drive doesn't report, are replaced without asking. nil
+// removes it. Set it before starting any downloads.
+func (c *Client) SetOverwriteConfirm(fn OverwriteConfirmFunc) {
+	c.confirmOverwrite = fn
+}
+
+// SetRecheckSize makes ExistSkip, before replacing a local copy whose size
+// differs from the listing's, fetch the file's current size and MD5 from
+// Drive. A listing from the cache may be out of date, and the copy is kept
+// if it matches the current size.
+func (c *Client) SetRecheckSize(on bool) {
+	c.recheckSize = on
+}
+
+// SetPageSize sets how many files each list request asks for, clamped to the
+// API's valid range of 1 to MaxPageSize.
+func (c *Client) SetPageSize(n int) {
+	c.pageSize = int64(min(max(n, 1), MaxPageSize))
+}
+
+// listPageSize returns the configured page size, or DefaultPageSize if unset
+func (c *Client) listPageSize() int64 {
+	if c.pageSize <= 0 {
+		return DefaultPageSize
+	}
+	return c.pageSize
 }
 
 // NewClientWithAPIKey creates a new Drive client using an API key
 func NewClientWithAPIKey(ctx context.Context, apiKey string) (*Client, error) {
-	if apiKey == "" {
-		return nil, fmt.Errorf("API key is required")
-	}
+	return NewClient(ctx, WithAPIKey(apiKey))
+}
 
-	srv, err := drive.NewService(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, fmt.Errorf("unable to create Drive service: %w", err)
+// apiKeyHTTPClient returns a client that adds apiKey to every request made through base.
+// Supplying our own HTTP client disables option.WithAPIKey, so the transport does it instead.
+func apiKeyHTTPClient(base *http.Client, apiKey string) *http.Client {
+	return &http.Client{Transport: &transport.APIKey{Key: apiKey, Transport: base.Transport}}
+}
+
+// scopeAliases maps the short names accepted by ParseScopes to Drive scope URLs
+var scopeAliases = map[string]string{
+	"readonly":          drive.DriveReadonlyScope,
+	"drive":             drive.DriveScope,
+	"full":              drive.DriveScope,
+	"file":              drive.DriveFileScope,
+	"metadata.readonly": drive.DriveMetadataReadonlyScope,
+}
+
+// ParseScopes converts a comma-separated list of scope names or URLs into
+// OAuth scope URLs. Short names are readonly, drive (or full), file, and
+// metadata.readonly. An empty string yields the default read-only scope.
+func ParseScopes(s string) ([]string, error) {
+	var scopes []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if url, ok := scopeAliases[part]; ok {
+			scopes = append(scopes, url)
+		} else if strings.HasPrefix(part, "https://") {
+			scopes = append(scopes, part)
+		} else {
+			return nil, fmt.Errorf("unknown scope %q (valid: readonly, drive, file, metadata.readonly, or a scope URL)", part)
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = []string{drive.DriveReadonlyScope}
 	}
+	return scopes, nil
+}
 
-	return &Client{service: srv}, nil
+// NewClientWithOAuth creates a new Drive client using OAuth credentials.
+// Scopes default to read-only when none are given.
+func NewClientWithOAuth(ctx context.Context, credentialsPath string, scopes ...string) (*Client, error) {
+	return NewClient(ctx, WithOAuth(credentialsPath, scopes...))
 }
 
-// NewClientWithOAuth creates a new Drive client using OAuth credentials
-func NewClientWithOAuth(ctx context.Context, credentialsPath string) (*Client, error) {
+// oauthHTTPClient returns a client authorized with the user's OAuth consent,
+// running the browser flow if there is no saved token for scopes.
+func oauthHTTPClient(ctx context.Context, credentialsPath string, scopes []string) (*http.Client, error) {
 	b, err := os.ReadFile(credentialsPath)
+	if errors.Is(err, fs.ErrNotExist) && os.Getenv(CredentialsEnv) != "" {
+		slog.Debug("credentials file not found, using environment", "path", credentialsPath, "env", CredentialsEnv)
+		b, err = []byte(os.Getenv(CredentialsEnv)), nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("unable to read credentials file: %w", err)
 	}
 
-	config, err := google.ConfigFromJSON(b, drive.DriveReadonlyScope)
+	kind := credentialsType(b)
+	if kind == "service_account" {
+		// A common mix-up; the key works fine, just not through the OAuth flow
+		slog.Info("Credentials file is a service account key, authenticating as the service account", "path", credentialsPath)
+		return jwtHTTPClient(ctx, b, scopes)
+	}
+	if kind != "oauth" {
+		return nil, fmt.Errorf("%s is neither an OAuth client (\"installed\" or \"web\") nor a service account key; download the OAuth client JSON from the Google Cloud console", credentialsPath)
+	}
+
+	config, err := google.ConfigFromJSON(b, scopes...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse credentials: %w", err)
 	}
 
-	client, err := getOAuthClient(ctx, config)
+	return getOAuthClient(ctx, config)
+}
+
+// serviceAccountHTTPClient returns a client authorized as the service account in keyPath
+func serviceAccountHTTPClient(ctx context.Context, keyPath string, scopes []string) (*http.Client, error) {
+	b, err := os.ReadFile(keyPath)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to read service account key: %w", err)
 	}
 
-	srv, err := drive.NewService(ctx, option.WithHTTPClient(client))
+	if credentialsType(b) == "oauth" {
+		return nil, fmt.Errorf("%s is an OAuth client file, not a service account key; pass it with -credentials instead", keyPath)
+	}
+
+	return jwtHTTPClient(ctx, b, scopes)
+}
+
+// jwtHTTPClient returns a client authorized by the service account key in b
+func jwtHTTPClient(ctx context.Context, b []byte, scopes []string) (*http.Client, error) {
+	config, err := google.JWTConfigFromJSON(b, scopes...)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create Drive service: %w", err)
+		return nil, fmt.Errorf("unable to parse service account key: %w", err)
 	}
 
-	return &Client{service: srv}, nil
+	return config.Client(ctx), nil
+}
+
+// credentialsType reports what kind of Google credentials JSON b holds:
+// "oauth" for an installed or web app client, "service_account" for a
+// service account key, or "" if it is neither
+func credentialsType(b []byte) string {
+	var f struct {
+		Type      string          `json:"type"`
+		Installed json.RawMessage `json:"installed"`
+		Web       json.RawMessage `json:"web"`
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return ""
+	}
+	switch {
+	case f.Type == "service_account":
+		return "service_account"
+	case f.Installed != nil || f.Web != nil:
+		return "oauth"
+	}
+	return ""
 }
 
-// getOAuthClient retrieves a token, saves it, and returns the generated client
+// getOAuthClient retrieves a token, saves it, and returns the generated client.
+// A saved token granted for different scopes, or one that can't be read, is
+// discarded so the user re-consents.
 func getOAuthClient(ctx context.Context, config *oauth2.Config) (*http.Client, error) {
 	tokFile := "token.json"
-	tok, err := tokenFromFile(tokFile)
+	tok, err := loadToken(tokFile, config.Scopes)
 	if err != nil {
-		tok, err = getTokenFromWeb(config)
+		switch {
+		case errors.Is(err, errScopeMismatch):
+			slog.Warn("saved token was granted for different scopes, re-authorizing", "file", tokFile)
+		case errors.Is(err, errCorruptToken):
+			// loadToken already warned about the token itself
+			slog.Info("re-authorizing")
+		}
+		tok, err = getTokenFromWeb(ctx, config)
 		if err != nil {
 			return nil, err
 		}
-		saveToken(tokFile, tok)
+		saveToken(tokFile, tok, config.Scopes)
 	}
 	return config.Client(ctx, tok), nil
 }
 
 // getTokenFromWeb starts a local server to capture the OAuth callback
-func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
+func getTokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
 	// Start listener on a random available port
 	listener, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
@@ -198,50 +510,174 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 	// Shutdown the server
 	server.Close()
 
-	tok, err := config.Exchange(context.TODO(), authCode)
+	tok, err := config.Exchange(ctx, authCode)
 	if err != nil {
 		return nil, fmt.Errorf("unable to exchange token: %w", err)
 	}
 	return tok, nil
 }
 
-// tokenFromFile retrieves a token from a local file
-func tokenFromFile(file string) (*oauth2.Token, error) {
+// errScopeMismatch is returned by tokenFromFile when the saved token was
+// granted for a different set of scopes than requested
+var errScopeMismatch = errors.New("saved token has different scopes")
+
+// errCorruptToken is returned by tokenFromFile when the saved token isn't valid JSON
+var errCorruptToken = errors.New("saved token is corrupt")
+
+// savedToken is the on-disk token format. Scopes is absent in tokens saved by
+// older versions, which only ever requested the read-only scope.
+type savedToken struct {
+	*oauth2.Token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// tokenFromFile retrieves a token from a local file and checks it was granted for scopes.
+// If the file doesn't exist, the token is read from TokenEnv instead.
+func tokenFromFile(file string, scopes []string) (*oauth2.Token, error) {
+	var r io.Reader
 	f, err := os.Open(file)
-	if err != nil {
+	switch {
+	case err == nil:
+		defer f.Close()
+		r = f
+	case errors.Is(err, fs.ErrNotExist) && os.Getenv(TokenEnv) != "":
+		r = strings.NewReader(os.Getenv(TokenEnv))
+	default:
 		return nil, err
 	}
-	defer f.Close()
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	return tok, err
+	saved := savedToken{Token: &oauth2.Token{}}
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("%w: %w", errCorruptToken, err)
+	}
+	if len(saved.Scopes) == 0 {
+		saved.Scopes = []string{drive.DriveReadonlyScope}
+	}
+	if !sameScopes(saved.Scopes, scopes) {
+		return nil, errScopeMismatch
+	}
+	return saved.Token, nil
 }
 
-// saveToken saves a token to a file
-func saveToken(path string, token *oauth2.Token) error {
+// loadToken is tokenFromFile, except that a corrupt token file is removed so
+// the next save starts clean. A corrupt token from TokenEnv is left alone.
+func loadToken(file string, scopes []string) (*oauth2.Token, error) {
+	tok, err := tokenFromFile(file, scopes)
+	if !errors.Is(err, errCorruptToken) {
+		return tok, err
+	}
+	if _, statErr := os.Stat(file); statErr != nil {
+		slog.Warn("token from the environment is corrupt", "env", TokenEnv, "err", err)
+		return nil, err
+	}
+	slog.Warn("removing corrupt token file", "path", file, "err", err)
+	if rmErr := os.Remove(file); rmErr != nil {
+		return nil, fmt.Errorf("unable to remove corrupt token: %w", rmErr)
+	}
+	return nil, err
+}
+
+// saveToken saves a token and the scopes it was granted for to a file
+func saveToken(path string, token *oauth2.Token, scopes []string) error {
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return fmt.Errorf("unable to save token: %w", err)
 	}
 	defer f.Close()
-	return json.NewEncoder(f).Encode(token)
+	return json.NewEncoder(f).Encode(savedToken{Token: token, Scopes: scopes})
+}
+
+// sameScopes reports whether a and b contain the same scopes in any order
+func sameScopes(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// ExtractFileID extracts the file ID from a Google Drive file link, a legacy
+// open?id= link, or a bare ID
+func ExtractFileID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if m := fileIDRegex.FindStringSubmatch(s); len(m) == 2 {
+		return m[1], nil
+	}
+	if m := openIDRegex.FindStringSubmatch(s); len(m) == 2 {
+		return m[1], nil
+	}
+	if bareIDRegex.MatchString(s) {
+		return s, nil
+	}
+	return "", fmt.Errorf("could not extract file ID from: %s", s)
 }
 
-// ExtractFolderID extracts the folder ID from a Google Drive URL
+// MyDriveLink is the link value that lists the authenticated user's own My
+// Drive. It is also the folder ID Drive accepts for it.
+const MyDriveLink = "root"
+
+// ErrMyDriveNeedsAuth is returned for MyDriveLink under API-key auth, which has no user
+var ErrMyDriveNeedsAuth = errors.New("listing My Drive needs OAuth or a service account; an API key only sees public files")
+
+// ExtractFolderID extracts the folder ID from a Google Drive URL, or returns
+// MyDriveLink itself
 func ExtractFolderID(url string) (string, error) {
+	if url == MyDriveLink {
+		return MyDriveLink, nil
+	}
 	// Handle formats like:
 	// https://drive.google.com/drive/folders/FOLDER_ID
 	// https://drive.google.com/drive/folders/FOLDER_ID?usp=drive_link
+	// https://drive.google.com/open?id=FOLDER_ID
 	matches := folderIDRegex.FindStringSubmatch(url)
+	if len(matches) < 2 {
+		matches = openIDRegex.FindStringSubmatch(url)
+	}
 	if len(matches) < 2 {
 		return "", fmt.Errorf("could not extract folder ID from URL: %s", url)
 	}
 	return matches[1], nil
 }
 
+// InvalidLink is a line of a links list that isn't a Google Drive folder link
+type InvalidLink struct {
+	// Line is the 1-based line number
+	Line int
+	Text string
+}
+
+// ParseLinks reads folder links from a list with one per line, as in a -f
+// links file. Blank lines and lines starting with # are ignored; any other
+// line that isn't a folder link is returned in invalid rather than dropped,
+// so typos can be reported.
+func ParseLinks(content string) (links []string, invalid []InvalidLink) {
+	for n, line := range listLines(content) {
+		if _, err := ExtractFolderID(line); err != nil {
+			invalid = append(invalid, InvalidLink{Line: n, Text: line})
+			continue
+		}
+		links = append(links, line)
+	}
+	return links, invalid
+}
+
+// listLines yields the trimmed lines of a list file with their 1-based line
+// numbers, skipping blank lines and lines starting with #
+func listLines(content string) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !yield(i+1, line) {
+				return
+			}
+		}
+	}
+}
+
 // ListFiles lists all files in a folder (non-recursive, for backward compatibility)
 func (c *Client) ListFiles(ctx context.Context, folderID string) ([]DriveFile, error) {
-	files, warnings, err := c.listFilesWithPath(ctx, folderID, "", 0, DefaultMaxDepth)
+	files, warnings, err := c.listFilesWithPath(ctx, folderID, "", 0, c.MaxDepth(), nil, make(map[string]string))
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +689,49 @@ func (c *Client) ListFiles(ctx context.Context, folderID string) ([]DriveFile, e
 
 // ListFilesRecursive lists all files in a folder and its subfolders up to maxDepth
 func (c *Client) ListFilesRecursive(ctx context.Context, folderID string, maxDepth int) ([]DriveFile, error) {
-	files, warnings, err := c.listFilesWithPath(ctx, folderID, "", 0, maxDepth)
+	return c.ListFilesRecursiveWithProgress(ctx, folderID, maxDepth, nil)
+}
+
+// ListProgressFunc receives running totals while a recursive listing walks the tree.
+type ListProgressFunc func(filesFound, foldersScanned int)
+
+// listObserver is notified as listFilesWithPath walks a folder tree. It keeps
+// running totals for onProgress and hands each page of files to onPage.
+type listObserver struct {
+	files      int
+	folders    int
+	onProgress ListProgressFunc
+	onPage     func([]DriveFile)
+}
+
+// page records a page of newly found files and any folders finished with it
+func (o *listObserver) page(files []DriveFile, folders int) {
+	if o == nil {
+		return
+	}
+	o.files += len(files)
+	o.folders += folders
+	if o.onPage != nil && len(files) > 0 {
+		o.onPage(files)
+	}
+	if o.onProgress != nil {
+		o.onProgress(o.files, o.folders)
+	}
+}
+
+// ListFilesRecursiveWithProgress is like ListFilesRecursive but calls onProgress
+// after every page and folder so callers can show how far the walk has got.
+func (c *Client) ListFilesRecursiveWithProgress(ctx context.Context, folderID string, maxDepth int, onProgress ListProgressFunc) ([]DriveFile, error) {
+	var obs *listObserver
+	if onProgress != nil {
+		obs = &listObserver{onProgress: onProgress}
+	}
+	return c.listFolderTree(ctx, folderID, maxDepth, obs)
+}
+
+// listFolderTree lists a folder recursively, folding subfolder warnings into the error
+func (c *Client) listFolderTree(ctx context.Context, folderID string, maxDepth int, obs *listObserver) ([]DriveFile, error) {
+	files, warnings, err := c.listFilesWithPath(ctx, folderID, "", 0, maxDepth, obs, make(map[string]string))
 	if err != nil {
 		return nil, err
 	}
@@ -263,8 +741,68 @@ func (c *Client) ListFilesRecursive(ctx context.Context, folderID string, maxDep
 	return files, nil
 }
 
-// listFilesWithPath is the internal recursive implementation
-func (c *Client) listFilesWithPath(ctx context.Context, folderID, currentPath string, currentDepth, maxDepth int) ([]DriveFile, []string, error) {
+// fileFields are the file fields requested from the API for a DriveFile
+const fileFields = "id, name, size, md5Checksum, mimeType, createdTime, modifiedTime, owners(displayName, emailAddress)"
+
+// fileFromAPI converts an API file found in folderID at path into a DriveFile
+func fileFromAPI(f *drive.File, path, folderID string) DriveFile {
+	file := DriveFile{
+		ID:          f.Id,
+		Name:        f.Name,
+		Path:        path,
+		Size:        f.Size,
+		MD5Checksum: f.Md5Checksum,
+		FolderID:    folderID,
+		MimeType:    f.MimeType,
+	}
+	if len(f.Owners) > 0 {
+		file.Owner = f.Owners[0].DisplayName
+		file.OwnerEmail = f.Owners[0].EmailAddress
+	}
+	if f.Size == 0 {
+		slog.Debug("file has no size metadata, size checks disabled", "id", f.Id, "name", f.Name, "mimeType", f.MimeType)
+	}
+
+	// Parse timestamps
+	if f.CreatedTime != "" {
+		if t, err := time.Parse(time.RFC3339, f.CreatedTime); err == nil {
+			file.CreatedTime = t
+		}
+	}
+	if f.ModifiedTime != "" {
+		if t, err := time.Parse(time.RFC3339, f.ModifiedTime); err == nil {
+			file.ModifiedTime = t
+		}
+	}
+	return file
+}
+
+// GetFile fetches the metadata of a single file by ID
+func (c *Client) GetFile(ctx context.Context, fileID string) (DriveFile, error) {
+	slog.Debug("Files.Get", "id", fileID)
+	f, err := withRetry(ctx, c, "Files.Get", func() (*drive.File, error) {
+		return c.service.Get(ctx, fileID, fileFields+", parents")
+	})
+	if err != nil {
+		return DriveFile{}, fmt.Errorf("unable to get file %s: %w", fileID, err)
+	}
+	if f.MimeType == "application/vnd.google-apps.folder" {
+		return DriveFile{}, fmt.Errorf("%s is a folder, not a file", fileID)
+	}
+
+	var parent string
+	if len(f.Parents) > 0 {
+		parent = f.Parents[0]
+	}
+	return fileFromAPI(f, "", parent), nil
+}
+
+// listFilesWithPath is the internal recursive implementation. visited maps
+// the folders already listed in this walk to their paths; a folder reached
+// again, through a shortcut loop or a second parent, is skipped with a warning.
+// The walk is sequential, so visited needs no lock.
+func (c *Client) listFilesWithPath(ctx context.Context, folderID, currentPath string, currentDepth, maxDepth int, obs *listObserver, visited map[string]string) ([]DriveFile, []string, error) {
+	visited[folderID] = currentPath
 	var files []DriveFile
 	var warnings []string
 	var subfolders []struct {
@@ -274,19 +812,14 @@ func (c *Client) listFilesWithPath(ctx context.Context, folderID, currentPath st
 	pageToken := ""
 
 	for {
+		pageStart := len(files)
 		query := fmt.Sprintf("'%s' in parents and trashed = false", folderID)
-		call := c.service.Files.List().
-			Q(query).
-			Fields("nextPageToken, files(id, name, size, mimeType, createdTime, modifiedTime)").
-			PageSize(DefaultPageSize)
-
-		if pageToken != "" {
-			call = call.PageToken(pageToken)
-		}
-
-		result, err := call.Context(ctx).Do()
+		slog.Debug("Files.List", "folder", folderID, "path", currentPath, "nextPage", pageToken != "")
+		result, err := withRetry(ctx, c, "Files.List", func() (*drive.FileList, error) {
+			return c.service.List(ctx, query, "nextPageToken, files("+fileFields+")", pageToken, c.listPageSize())
+		})
 		if err != nil {
-			return nil, nil, fmt.Errorf("unable to list files: %w", err)
+			return nil, nil, c.WithQuotaAdvice(listError(folderID, err))
 		}
 
 		for _, f := range result.Files {
@@ -303,44 +836,31 @@ func (c *Client) listFilesWithPath(ctx context.Context, folderID, currentPath st
 				continue
 			}
 
-			file := DriveFile{
-				ID:       f.Id,
-				Name:     f.Name,
-				Path:     currentPath,
-				Size:     f.Size,
-				FolderID: folderID,
-				MimeType: f.MimeType,
-			}
-
-			// Parse timestamps
-			if f.CreatedTime != "" {
-				if t, err := time.Parse(time.RFC3339, f.CreatedTime); err == nil {
-					file.CreatedTime = t
-				}
-			}
-			if f.ModifiedTime != "" {
-				if t, err := time.Parse(time.RFC3339, f.ModifiedTime); err == nil {
-					file.ModifiedTime = t
-				}
-			}
-
-			files = append(files, file)
+			files = append(files, fileFromAPI(f, currentPath, folderID))
 		}
 
 		pageToken = result.NextPageToken
 		if pageToken == "" {
+			// Count this folder as scanned along with its last page
+			obs.page(files[pageStart:], 1)
 			break
 		}
+		obs.page(files[pageStart:], 0)
 	}
 
 	// Recursively process subfolders
 	for _, subfolder := range subfolders {
-		subPath := subfolder.name
+		subPath := escapeSeparators(subfolder.name)
 		if currentPath != "" {
-			subPath = currentPath + "/" + subfolder.name
+			subPath = currentPath + "/" + escapeSeparators(subfolder.name)
 		}
 
-		subFiles, subWarnings, err := c.listFilesWithPath(ctx, subfolder.id, subPath, currentDepth+1, maxDepth)
+		if first, seen := visited[subfolder.id]; seen {
+			slog.Warn("skipping folder already listed", "folder", subfolder.id, "path", subPath, "first", first)
+			warnings = append(warnings, fmt.Sprintf("subfolder '%s': skipped, already listed as '%s'", subPath, cmp.Or(first, "/")))
+			continue
+		}
+		subFiles, subWarnings, err := c.listFilesWithPath(ctx, subfolder.id, subPath, currentDepth+1, maxDepth, obs, visited)
 		if err != nil {
 			// Collect warning but continue with other folders
 			warnings = append(warnings, fmt.Sprintf("subfolder '%s': %v", subPath, err))
@@ -353,49 +873,99 @@ func (c *Client) listFilesWithPath(ctx context.Context, folderID, currentPath st
 	return files, warnings, nil
 }
 
-// ListFilesFromFolders lists files from multiple folder URLs (recursively)
+// ListFilesFromFolders lists files from multiple folder URLs (recursively).
+// Files found under more than one link are listed once (see DedupeFiles).
 func (c *Client) ListFilesFromFolders(ctx context.Context, folderURLs []string) ([]DriveFile, error) {
-	return c.ListFilesFromFoldersWithDepth(ctx, folderURLs, 10)
+	return c.ListFilesFromFoldersWithDepth(ctx, folderURLs, c.MaxDepth(), DefaultMaxConcurrent)
 }
 
-// ListFilesFromFoldersWithDepth lists files from multiple folder URLs with specified max depth
-func (c *Client) ListFilesFromFoldersWithDepth(ctx context.Context, folderURLs []string, maxDepth int) ([]DriveFile, error) {
-	var allFiles []DriveFile
-	var mu sync.Mutex
+// ListFilesFromFoldersWithDepth lists files from multiple folder URLs with specified max depth.
+// At most maxConcurrent folders are listed at once to avoid bursting the API quota.
+func (c *Client) ListFilesFromFoldersWithDepth(ctx context.Context, folderURLs []string, maxDepth, maxConcurrent int) ([]DriveFile, error) {
+	return c.ListFilesFromFoldersStream(ctx, folderURLs, maxDepth, maxConcurrent, nil)
+}
+
+// ListFilesFromFoldersStream is like ListFilesFromFoldersWithDepth but also sends
+// each page of files to pages as soon as it arrives, so callers can show results
+// before the whole walk finishes. The channel is not closed; the full list is
+// still returned when listing completes.
+func (c *Client) ListFilesFromFoldersStream(ctx context.Context, folderURLs []string, maxDepth, maxConcurrent int, pages chan<- []DriveFile) ([]DriveFile, error) {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+
+	// Each link's files go in its own slot so duplicates keep the first link's path
+	results := make([][]DriveFile, len(folderURLs))
 	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrent)
 	errChan := make(chan error, len(folderURLs))
 
-	for _, url := range folderURLs {
+	for i, url := range folderURLs {
 		url = strings.TrimSpace(url)
 		if url == "" {
 			continue
 		}
 
 		wg.Add(1)
-		go func(u string) {
+		go func(i int, u string) {
 			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
 			folderID, err := ExtractFolderID(u)
 			if err != nil {
 				errChan <- err
 				return
 			}
+			if folderID == MyDriveLink && c.apiKey {
+				errChan <- ErrMyDriveNeedsAuth
+				return
+			}
 
-			files, err := c.ListFilesRecursive(ctx, folderID, maxDepth)
+			// Tag every file with the link it came from
+			rootName := c.folderName(ctx, folderID)
+			tag := func(files []DriveFile) {
+				for i := range files {
+					files[i].RootFolderID = folderID
+					files[i].RootFolderName = rootName
+				}
+			}
+
+			var obs *listObserver
+			if pages != nil {
+				obs = &listObserver{onPage: func(page []DriveFile) {
+					page = append([]DriveFile(nil), page...)
+					tag(page)
+					select {
+					case pages <- page:
+					case <-ctx.Done():
+					}
+				}}
+			}
+
+			files, err := c.listFolderTree(ctx, folderID, maxDepth, obs)
 			if err != nil {
+				// Keep whatever was listed; a failing subfolder shouldn't lose its siblings
 				errChan <- fmt.Errorf("folder %s: %w", folderID, err)
-				return
+				if len(files) == 0 {
+					return
+				}
 			}
 
-			mu.Lock()
-			allFiles = append(allFiles, files...)
-			mu.Unlock()
-		}(url)
+			tag(files)
+			results[i] = files
+		}(i, url)
 	}
 
 	wg.Wait()
 	close(errChan)
 
+	allFiles, dropped := DedupeFiles(slices.Concat(results...), c.dedupeContent)
+	c.duplicates.Store(int64(dropped))
+	if dropped > 0 {
+		slog.Info("collapsed duplicate files found under more than one link", "count", dropped)
+	}
+
 	// Collect any errors
 	var errs []string
 	for err := range errChan {
@@ -408,17 +978,52 @@ func (c *Client) ListFilesFromFoldersWithDepth(ctx context.Context, folderURLs [
 	return allFiles, nil
 }
 
-// FilterFiles filters files by search terms (OR logic - matches any term)
+// folderName returns the name of a folder, or "" if it can't be fetched
+func (c *Client) folderName(ctx context.Context, folderID string) string {
+	slog.Debug("Files.Get", "id", folderID, "fields", "name")
+	c.apiCalls.Add(1)
+	f, err := c.service.Get(ctx, folderID, "name")
+	if err != nil {
+		return ""
+	}
+	return f.Name
+}
+
+// FilterFiles filters files by search terms (OR logic - matches any term).
+// Terms are matched against the folder path as well as the name, so searching
+// for a subfolder name finds every file beneath it. A term starting with
+// GlobPrefix is a glob matched against the name alone.
 func FilterFiles(files []DriveFile, searchTerms []string) []DriveFile {
+	return filterFiles(files, searchTerms, false)
+}
+
+// FilterFilesByName is like FilterFiles but only matches the file name
+func FilterFilesByName(files []DriveFile, searchTerms []string) []DriveFile {
+	return filterFiles(files, searchTerms, true)
+}
+
+func filterFiles(files []DriveFile, searchTerms []string, nameOnly bool) []DriveFile {
 	if len(searchTerms) == 0 {
 		return files
 	}
 
 	var filtered []DriveFile
 	for _, f := range files {
-		nameLower := strings.ToLower(f.Name)
+		haystack := f.DisplayName()
+		if nameOnly {
+			haystack = f.Name
+		}
+		nameLower := strings.ToLower(haystack)
 		for _, term := range searchTerms {
-			if strings.Contains(nameLower, strings.ToLower(strings.TrimSpace(term))) {
+			term = strings.TrimSpace(term)
+			if pattern, ok := strings.CutPrefix(term, GlobPrefix); ok {
+				if MatchGlob(pattern, f.Name) {
+					filtered = append(filtered, f)
+					break
+				}
+				continue
+			}
+			if strings.Contains(nameLower, strings.ToLower(term)) {
 				filtered = append(filtered, f)
 				break
 			}
@@ -427,71 +1032,205 @@ func FilterFiles(files []DriveFile, searchTerms []string) []DriveFile {
 	return filtered
 }
 
-// DownloadFile downloads a file to the specified directory
-func (c *Client) DownloadFile(ctx context.Context, file DriveFile, destDir string, progressChan chan<- DownloadProgress) error {
-	// Build the full destination path including subfolder structure
-	fullDestDir := destDir
-	if file.Path != "" {
-		fullDestDir = fmt.Sprintf("%s/%s", destDir, file.Path)
-	}
-
-	destPath := fmt.Sprintf("%s/%s", fullDestDir, file.Name)
-
-	// Check if file already exists with same size
-	if info, err := os.Stat(destPath); err == nil {
-		if info.Size() == file.Size {
-			// File exists and has same size, skip download
-			if progressChan != nil {
-				progressChan <- DownloadProgress{
-					FileID:      file.ID,
-					FileName:    file.DisplayName(),
-					BytesLoaded: file.Size,
-					TotalBytes:  file.Size,
-					Done:        true,
-					Skipped:     true,
-				}
-			}
-			return nil
+// ParseSince parses a -since value relative to now: a date (2024-01-01), an
+// RFC 3339 timestamp, or a duration back from now (168h, or 7d for days).
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid number of days %q", value)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("%q is not a date (2024-01-01) or a duration (168h, 7d)", value)
+	}
+	return now.Add(-d), nil
+}
+
+// FilterModifiedSince returns the files modified at or after since. Files
+// without a modified time are left out, since they can't be shown to match.
+// A zero since returns files unchanged.
+func FilterModifiedSince(files []DriveFile, since time.Time) []DriveFile {
+	if since.IsZero() {
+		return files
+	}
+
+	var filtered []DriveFile
+	for _, f := range files {
+		if !f.ModifiedTime.IsZero() && !f.ModifiedTime.Before(since) {
+			filtered = append(filtered, f)
 		}
 	}
+	return filtered
+}
 
-	resp, err := c.service.Files.Get(file.ID).Context(ctx).Download()
+// DownloadFile downloads a file to the specified directory. Besides
+// progressChan, every step is reported to the client's EventHandler, if set.
+func (c *Client) DownloadFile(ctx context.Context, file DriveFile, destDir string, progressChan chan<- DownloadProgress) error {
+	ctx = contextWithDownload(ctx, file, progressChan)
+	err := c.downloadFile(ctx, file, destDir, progressChan)
 	if err != nil {
-		return fmt.Errorf("unable to download file: %w", err)
+		c.emit(Event{Kind: EventFailed, File: file, TotalBytes: file.Size, Err: err})
 	}
-	defer resp.Body.Close()
+	return err
+}
+
+// downloadFile does the work of DownloadFile, emitting every event but EventFailed
+func (c *Client) downloadFile(ctx context.Context, file DriveFile, destDir string, progressChan chan<- DownloadProgress) error {
+	// Build the full destination path including subfolder structure,
+	// refusing names that would escape destDir
+	destPath, err := c.DestPath(destDir, file)
+	if err != nil {
+		return err
+	}
+	fullDestDir := filepath.Dir(destPath)
 
-	// Create subdirectories if they don't exist
-	if file.Path != "" {
-		if err := os.MkdirAll(fullDestDir, 0755); err != nil {
-			return fmt.Errorf("unable to create directory %s: %w", fullDestDir, err)
+	// Decide what to do with an existing local copy. A revision is saved
+	// under its own name (see AtRevision), so it is always fetched.
+	policy := c.existPolicy
+	if file.RevisionID != "" {
+		policy = ExistOverwrite
+	}
+	switch policy {
+	case ExistSkip, ExistNewer:
+		if IsLocalCopyCurrent(destPath, file, policy) {
+			// Local copy is up to date, skip download
+			slog.Debug("skipping existing file", "file", file.DisplayName(), "policy", policy)
+			c.skipFile(file, destPath, progressChan)
+			return nil
+		}
+		if policy == ExistSkip && c.recheckSize && localSizeDiffers(destPath, file) {
+			file = c.refreshSize(ctx, file)
+			if IsLocalCopyCurrent(destPath, file, policy) {
+				slog.Debug("skipping existing file, the listed size was out of date", "file", file.DisplayName())
+				c.skipFile(file, destPath, progressChan)
+				return nil
+			}
 		}
+	case ExistOverwrite:
+		if c.confirmOverwrite != nil && localSizeDiffers(destPath, file) && !c.confirmOverwrite(ctx, file, destPath) {
+			slog.Debug("keeping changed local file", "file", file.DisplayName(), "path", destPath)
+			c.skipFile(file, destPath, progressChan)
+			return nil
+		}
+	case ExistRename:
+		destPath = nextFreePath(destPath)
+	}
+	slog.Debug("downloading file", "file", file.DisplayName(), "size", file.Size, "dest", destPath)
+	c.emit(Event{Kind: EventStarted, File: file, TotalBytes: file.Size, LocalPath: destPath})
+
+	// Create subdirectories if they don't exist. The output structure may
+	// put even a root-level file into one.
+	if err := os.MkdirAll(fullDestDir, 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s: %w", fullDestDir, err)
 	}
 
-	out, err := os.Create(destPath)
+	// Write to a .part file so an interrupted download never looks complete
+	partPath := destPath + PartSuffix
+	out, err := os.Create(partPath)
 	if err != nil {
 		return fmt.Errorf("unable to create file: %w", err)
 	}
-	defer out.Close()
 
-	// Create a progress reader if channel provided
-	var reader io.Reader = resp.Body
+	err = c.writeTo(ctx, file, out, progressChan)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return err
+	}
+
+	if err := os.Rename(partPath, destPath); err != nil {
+		os.Remove(partPath)
+		return fmt.Errorf("unable to finalize file: %w", err)
+	}
+
+	c.emit(Event{Kind: EventCompleted, File: file, BytesLoaded: file.Size, TotalBytes: file.Size, LocalPath: destPath})
+
+	// Send final progress
 	if progressChan != nil {
-		reader = &progressReader{
-			reader:       resp.Body,
-			fileID:       file.ID,
-			fileName:     file.DisplayName(),
-			totalBytes:   file.Size,
-			progressChan: progressChan,
+		progressChan <- DownloadProgress{
+			FileID:      file.ID,
+			FileName:    file.DisplayName(),
+			BytesLoaded: file.Size,
+			TotalBytes:  file.Size,
+			Done:        true,
+			LocalPath:   destPath,
+		}
+	}
+
+	return nil
+}
+
+// skipFile reports file as skipped, its local copy at destPath left as it is
+func (c *Client) skipFile(file DriveFile, destPath string, progressChan chan<- DownloadProgress) {
+	c.emit(Event{Kind: EventSkipped, File: file, BytesLoaded: file.Size, TotalBytes: file.Size, LocalPath: destPath})
+	if progressChan != nil {
+		progressChan <- DownloadProgress{
+			FileID:      file.ID,
+			FileName:    file.DisplayName(),
+			BytesLoaded: file.Size,
+			TotalBytes:  file.Size,
+			Done:        true,
+			Skipped:     true,
+			LocalPath:   destPath,
 		}
 	}
+}
 
-	_, err = io.Copy(out, reader)
+// refreshSize returns file with the size and MD5 Drive reports now. If they
+// can't be fetched, file is returned as it was listed.
+func (c *Client) refreshSize(ctx context.Context, file DriveFile) DriveFile {
+	slog.Debug("Files.Get", "id", file.ID, "fields", "size, md5Checksum")
+	f, err := withRetry(ctx, c, "Files.Get", func() (*drive.File, error) {
+		return c.service.Get(ctx, file.ID, "size, md5Checksum")
+	})
 	if err != nil {
-		return fmt.Errorf("unable to save file: %w", err)
+		slog.Debug("unable to recheck size", "file", file.DisplayName(), "error", err)
+		return file
+	}
+	if f.Size == 0 && f.Md5Checksum == "" {
+		return file // nothing to compare against
+	}
+	if f.Size != file.Size {
+		slog.Debug("listed size was out of date", "file", file.DisplayName(), "listed", file.Size, "current", f.Size)
 	}
+	file.Size = f.Size
+	file.MD5Checksum = f.Md5Checksum
+	return file
+}
 
-	// Send final progress
+// localSizeDiffers reports whether a regular file exists at path with a size
+// other than file's, when Drive reports one
+func localSizeDiffers(path string, file DriveFile) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && file.Size > 0 && info.Size() != file.Size
+}
+
+// DownloadFileTo streams the file's content into w instead of a local file,
+// e.g. to pipe it into another process. Progress and events are reported as
+// for DownloadFile; nothing is skipped, since there is no local copy to check.
+func (c *Client) DownloadFileTo(ctx context.Context, file DriveFile, w io.Writer, progressChan chan<- DownloadProgress) error {
+	ctx = contextWithDownload(ctx, file, progressChan)
+	c.emit(Event{Kind: EventStarted, File: file, TotalBytes: file.Size})
+
+	if err := c.writeTo(ctx, file, w, progressChan); err != nil {
+		c.emit(Event{Kind: EventFailed, File: file, TotalBytes: file.Size, Err: err})
+		return err
+	}
+
+	c.emit(Event{Kind: EventCompleted, File: file, BytesLoaded: file.Size, TotalBytes: file.Size})
 	if progressChan != nil {
 		progressChan <- DownloadProgress{
 			FileID:      file.ID,
@@ -501,14 +1240,188 @@ func (c *Client) DownloadFile(ctx context.Context, file DriveFile, destDir strin
 			Done:        true,
 		}
 	}
+	return nil
+}
+
+// writeTo copies the file's content into w. Large files go as concurrent
+// segments when w is a regular file that can be written at any offset.
+func (c *Client) writeTo(ctx context.Context, file DriveFile, w io.Writer, progressChan chan<- DownloadProgress) error {
+	if _, mimeType, ok := c.exportFormat(file); ok {
+		return c.downloadExport(ctx, file, mimeType, w, progressChan)
+	}
+	if out, ok := w.(*os.File); ok && file.RevisionID == "" && c.segments > 1 && file.Size > 0 && file.Size >= c.segmentThreshold && isRegularFile(out) {
+		err := c.downloadSegmented(ctx, file, out, progressChan)
+		if !errors.Is(err, errRangeUnsupported) {
+			return err
+		}
+		// Server ignored the Range header, start over with a single stream
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	return c.downloadStream(ctx, file, w, progressChan)
+}
+
+// downloadExport converts a Google Workspace file to mimeType and copies the
+// result into w. Drive doesn't know the exported size up front.
+func (c *Client) downloadExport(ctx context.Context, file DriveFile, mimeType string, w io.Writer, progressChan chan<- DownloadProgress) error {
+	slog.Debug("Files.Export download", "id", file.ID, "mimeType", mimeType)
+	resp, err := withRetry(ctx, c, "Files.Export download", func() (*http.Response, error) {
+		return c.service.Export(ctx, file.ID, mimeType)
+	})
+	if err != nil {
+		return apiError("export file", err)
+	}
+	defer resp.Body.Close()
+	return c.copyWithProgress(file, resp.Body, w, progressChan)
+}
+
+// isRegularFile reports whether f is a regular file rather than a pipe or device
+func isRegularFile(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode().IsRegular()
+}
+
+// downloadStream copies the whole file into w with a single request
+func (c *Client) downloadStream(ctx context.Context, file DriveFile, w io.Writer, progressChan chan<- DownloadProgress) error {
+	op := "Files.Get download"
+	if file.RevisionID != "" {
+		op = "Revisions.Get download"
+	}
+	slog.Debug(op, "id", file.ID, "revision", file.RevisionID)
+	resp, err := withRetry(ctx, c, op, func() (*http.Response, error) {
+		if file.RevisionID != "" {
+			return c.service.DownloadRevision(ctx, file.ID, file.RevisionID)
+		}
+		return c.service.Download(ctx, file.ID, nil)
+	})
+	if err != nil {
+		return apiError("download file", err)
+	}
+	defer resp.Body.Close()
+	return c.copyWithProgress(file, resp.Body, w, progressChan)
+}
 
+// copyWithProgress copies body into w, reporting progress if anyone is listening
+func (c *Client) copyWithProgress(file DriveFile, body io.Reader, w io.Writer, progressChan chan<- DownloadProgress) error {
+	var reader io.Reader = body
+	if progressChan != nil || c.events != nil {
+		reader = &progressReader{
+			reader:       body,
+			fileID:       file.ID,
+			fileName:     file.DisplayName(),
+			totalBytes:   file.Size,
+			progressChan: progressChan,
+			onRead: func(loaded int64) {
+				c.emit(Event{Kind: EventProgress, File: file, BytesLoaded: loaded, TotalBytes: file.Size})
+			},
+		}
+	}
+
+	if _, err := io.Copy(w, reader); err != nil {
+		return fmt.Errorf("unable to save file: %w", err)
+	}
 	return nil
 }
 
-// DownloadFiles downloads multiple files in parallel
+// IsLocalCopyCurrent reports whether the file at localPath is an up-to-date copy
+// of file. Under ExistNewer that means the local file is at least as new as the
+// remote one; under every other policy it means the sizes match. Only the final
+// path is considered, under any Unicode normalization of its names; a leftover
+// .part never counts as a finished copy.
+//
+// When Drive reported no size, size equality means nothing, so the MD5
+// checksum is compared instead; without one the copy is never considered current.
+// Exported Workspace files have neither, so they are current under every
+// policy as long as they were saved after Drive's last modification.
+func IsLocalCopyCurrent(localPath string, file DriveFile, policy ExistPolicy) bool {
+	localPath = existingPath(localPath)
+	info, err := os.Stat(localPath)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if _, exported := exportFormats[file.MimeType]; policy == ExistNewer || exported {
+		return !file.ModifiedTime.After(info.ModTime())
+	}
+	if file.Size == 0 {
+		return file.MD5Checksum != "" && fileMD5(localPath) == file.MD5Checksum
+	}
+	return info.Size() == file.Size
+}
+
+// fileMD5 returns the hex MD5 of the file at path, or "" if it can't be read
+func fileMD5(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LocalState describes what is on disk for a remote file.
+type LocalState int
+
+const (
+	// LocalMissing means there is no usable local copy
+	LocalMissing LocalState = iota
+	// LocalComplete means the final file exists and is current
+	LocalComplete
+	// LocalPartial means an interrupted download left a resumable .part file
+	LocalPartial
+)
+
+// LocalFileState reports whether localPath holds a current copy of file, and
+// if not, whether a partial download of it can be resumed.
+func LocalFileState(localPath string, file DriveFile, policy ExistPolicy) LocalState {
+	if IsLocalCopyCurrent(localPath, file, policy) {
+		return LocalComplete
+	}
+	info, err := os.Stat(existingPath(localPath + PartSuffix))
+	if err == nil && info.Mode().IsRegular() && info.Size() > 0 && info.Size() < file.Size {
+		return LocalPartial
+	}
+	return LocalMissing
+}
+
+// nextFreePath returns path if nothing exists there, otherwise the first
+// "name (N).ext" variant that is free
+func nextFreePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// DownloadFiles downloads multiple files in parallel. Overall progress goes
+// to the client's BatchHandler, if set.
 func (c *Client) DownloadFiles(ctx context.Context, files []DriveFile, destDir string, maxConcurrent int, progressChan chan<- DownloadProgress) error {
 	if maxConcurrent <= 0 {
-		maxConcurrent = 4
+		maxConcurrent = DefaultMaxConcurrent
+	}
+
+	// Route progress through the batch tracker on its way to the caller
+	var batchDone chan struct{}
+	if c.batch != nil {
+		in := make(chan DownloadProgress, maxConcurrent)
+		batchDone = make(chan struct{})
+		go c.reportBatch(NewBatchTracker(files), in, progressChan, batchDone)
+		progressChan = in
 	}
 
 	sem := make(chan struct{}, maxConcurrent)
@@ -538,6 +1451,10 @@ func (c *Client) DownloadFiles(ctx context.Context, files []DriveFile, destDir s
 
 	wg.Wait()
 	close(errChan)
+	if batchDone != nil {
+		close(progressChan)
+		<-batchDone
+	}
 
 	var errs []string
 	for err := range errChan {
@@ -550,6 +1467,37 @@ func (c *Client) DownloadFiles(ctx context.Context, files []DriveFile, destDir s
 	return nil
 }
 
+// Progress updates are sent at most every progressInterval, or sooner once
+// progressBytes more have arrived, instead of on every read
+const (
+	progressInterval = 100 * time.Millisecond
+	progressBytes    = 4 << 20 // 4 MiB
+)
+
+// progressThrottle decides which progress updates are sent. It is safe for
+// concurrent use, so the segments of a download can share one.
+type progressThrottle struct {
+	mu       sync.Mutex
+	lastTime time.Time
+	lastSent int64
+}
+
+// due reports whether an update for loaded bytes should be sent now. final
+// sends any bytes not reported yet, e.g. at the end of the body.
+func (t *progressThrottle) due(loaded int64, final bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if loaded <= t.lastSent {
+		return false
+	}
+	if !final && loaded-t.lastSent < progressBytes && time.Since(t.lastTime) < progressInterval {
+		return false
+	}
+	t.lastTime = time.Now()
+	t.lastSent = loaded
+	return true
+}
+
 // progressReader wraps an io.Reader to report progress
 type progressReader struct {
 	reader       io.Reader
@@ -558,13 +1506,23 @@ type progressReader struct {
 	bytesRead    int64
 	totalBytes   int64
 	progressChan chan<- DownloadProgress
+	onRead       func(bytesRead int64)
+	throttle     progressThrottle
 }
 
 func (pr *progressReader) Read(p []byte) (int, error) {
 	n, err := pr.reader.Read(p)
 	pr.bytesRead += int64(n)
 
-	if pr.progressChan != nil && n > 0 {
+	if !pr.throttle.due(pr.bytesRead, err != nil) {
+		return n, err
+	}
+
+	if pr.onRead != nil {
+		pr.onRead(pr.bytesRead)
+	}
+
+	if pr.progressChan != nil {
 		pr.progressChan <- DownloadProgress{
 			FileID:      pr.fileID,
 			FileName:    pr.fileName,
@@ -0,0 +1,323 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestExtractFolderID(t *testing.T) {
+	const id = "1AbC-dEf_GhI23"
+
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{name: "folder link", url: "https://drive.google.com/drive/folders/" + id, want: id},
+		{name: "usp sharing", url: "https://drive.google.com/drive/folders/" + id + "?usp=sharing", want: id},
+		{name: "usp drive_link", url: "https://drive.google.com/drive/folders/" + id + "?usp=drive_link", want: id},
+		{name: "user index", url: "https://drive.google.com/drive/u/0/folders/" + id, want: id},
+		{name: "user index with query", url: "https://drive.google.com/drive/u/2/folders/" + id + "?usp=sharing", want: id},
+		{name: "trailing slash", url: "https://drive.google.com/drive/folders/" + id + "/", want: id},
+		{name: "no scheme", url: "drive.google.com/drive/folders/" + id, want: id},
+		{name: "open id", url: "https://drive.google.com/open?id=" + id, want: id},
+		{name: "open id with other params", url: "https://drive.google.com/open?authuser=0&id=" + id, want: id},
+		{name: "my drive", url: MyDriveLink, want: "root"},
+		{name: "empty", url: "", wantErr: true},
+		{name: "not a link", url: "hello world", wantErr: true},
+		{name: "folders without id", url: "https://drive.google.com/drive/folders/", wantErr: true},
+		{name: "drive home", url: "https://drive.google.com/drive/my-drive", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractFolderID(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ExtractFolderID(%q) = %q, want error", tt.url, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractFolderID(%q) returned error: %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractFolderID(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "date", value: "2024-01-01", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)},
+		{name: "timestamp", value: "2024-01-01T08:30:00Z", want: time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)},
+		{name: "hours", value: "168h", want: now.Add(-168 * time.Hour)},
+		{name: "days", value: "7d", want: now.AddDate(0, 0, -7)},
+		{name: "padded", value: " 7d ", want: now.AddDate(0, 0, -7)},
+		{name: "empty", value: "", wantErr: true},
+		{name: "bad days", value: "xd", wantErr: true},
+		{name: "negative", value: "-2h", wantErr: true},
+		{name: "words", value: "last week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSince(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSince(%q) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSince(%q) returned error: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	const link = "https://drive.google.com/drive/folders/1AbC-dEf_GhI23"
+	content := "# Season 1\n" + link + "\n\n  # indented note\nhttps://drive.google.com/drive/folderz/typo\n" + link + "?usp=sharing\n"
+
+	links, invalid := ParseLinks(content)
+	if len(links) != 2 || links[0] != link || links[1] != link+"?usp=sharing" {
+		t.Errorf("ParseLinks links = %q, want the two folder links", links)
+	}
+	if len(invalid) != 1 || invalid[0].Line != 5 || invalid[0].Text != "https://drive.google.com/drive/folderz/typo" {
+		t.Errorf("ParseLinks invalid = %+v, want the typo on line 5", invalid)
+	}
+}
+
+func TestParseExportMap(t *testing.T) {
+	m, err := ParseExportMap("document=PDF, spreadsheet=.csv")
+	if err != nil {
+		t.Fatalf("ParseExportMap returned error: %v", err)
+	}
+	if m[mimeDocument] != "pdf" || m[mimeSpreadsheet] != "csv" || m[mimePresentation] != "pptx" {
+		t.Errorf("ParseExportMap = %v, want pdf/csv overrides on top of the defaults", m)
+	}
+
+	for _, value := range []string{"document", "sheet=csv", "document=xlsx"} {
+		if _, err := ParseExportMap(value); err == nil {
+			t.Errorf("ParseExportMap(%q) succeeded, want error", value)
+		}
+	}
+
+	c := &Client{exports: m}
+	doc := DriveFile{Name: "Notes", MimeType: mimeDocument}
+	if got := c.ExportedFile(doc).Name; got != "Notes.pdf" {
+		t.Errorf("ExportedFile name = %q, want Notes.pdf", got)
+	}
+	plain := DriveFile{Name: "photo.jpg", MimeType: "image/jpeg"}
+	if got := c.ExportedFile(plain).Name; got != "photo.jpg" {
+		t.Errorf("ExportedFile name = %q, want it unchanged", got)
+	}
+}
+
+func TestNameTemplate(t *testing.T) {
+	for _, text := range []string{"{{.Nmae}}", "{{.Path}}/{{.Name}}", "{{if false}}x{{end}}", "{{.Name"} {
+		if _, err := ParseNameTemplate(text); err == nil {
+			t.Errorf("ParseNameTemplate(%q) succeeded, want an error", text)
+		}
+	}
+
+	names, err := ParseNameTemplate(`{{.ModifiedTime.Format "2006-01-02"}} {{.Name}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{names: names}
+	file := DriveFile{Name: "Notes", MimeType: mimeDocument, ModifiedTime: time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)}
+	if got := c.SavedFile(file).Name; got != "2024-03-09 Notes.docx" {
+		t.Errorf("SavedFile name = %q, want 2024-03-09 Notes.docx", got)
+	}
+}
+
+func TestOutputStructure(t *testing.T) {
+	if _, err := ParseOutputStructure("by-size"); err == nil {
+		t.Error("ParseOutputStructure accepted an unknown structure")
+	}
+	file := DriveFile{Name: "beach.jpg", Path: "Trips/2023", MimeType: "image/jpeg", ModifiedTime: time.Date(2024, 1, 20, 0, 0, 0, 0, time.Local)}
+	for value, want := range map[string]string{
+		"":       "Trips/2023",
+		"mirror": "Trips/2023",
+		"flat":   "",
+		"date":   "2024/01",
+		"Type":   "images",
+	} {
+		s, err := ParseOutputStructure(value)
+		if err != nil {
+			t.Fatalf("ParseOutputStructure(%q): %v", value, err)
+		}
+		c := &Client{structure: s}
+		if got := c.SavedFile(file).Path; got != want {
+			t.Errorf("%q: saved under %q, want %q", value, got, want)
+		}
+	}
+	c := &Client{flattenDelim: DefaultFlattenDelimiter}
+	if got := c.SavedFile(file); got.Path != "" || got.Name != "Trips__2023__beach.jpg" {
+		t.Errorf("flattened to %q in %q, want Trips__2023__beach.jpg in the output directory", got.Name, got.Path)
+	}
+	c.structure = StructureDate
+	if got := c.SavedFile(file).Name; got != "2024__01__beach.jpg" {
+		t.Errorf("flattened by date to %q, want 2024__01__beach.jpg", got)
+	}
+	deep := file
+	deep.Path = strings.Repeat("Some rather long folder name/", 12)
+	other := deep
+	other.Path += "one more"
+	c.structure = StructureMirror
+	long, otherLong := c.SavedFile(deep).Name, c.SavedFile(other).Name
+	if len(long) > maxNameBytes || !strings.HasSuffix(long, "__beach.jpg") || !strings.HasPrefix(long, "Some rather long") {
+		t.Errorf("flattened a deep path to %q (%d bytes), want at most %d ending in the file name", long, len(long), maxNameBytes)
+	}
+	if long == otherLong {
+		t.Errorf("two deep folders both flattened to %q", long)
+	}
+	if _, err := ParseFlattenDelimiter("a/b"); err == nil {
+		t.Error("ParseFlattenDelimiter accepted a path separator")
+	}
+	if got := typeFolder(mimeSpreadsheet); got != "documents" {
+		t.Errorf("typeFolder(spreadsheet) = %q, want documents", got)
+	}
+}
+
+func TestDedupeFiles(t *testing.T) {
+	files := []DriveFile{
+		{ID: "a", Path: "first", RootFolderID: "r1", Size: 5, MD5Checksum: "m1"},
+		{ID: "b", RootFolderID: "r1", Size: 5, MD5Checksum: "m1"},
+		{ID: "a", Path: "second", RootFolderID: "r2", Size: 5, MD5Checksum: "m1"},
+		{ID: "c", RootFolderID: "r2", Size: 5, MD5Checksum: "m1"},
+		{ID: "d", RootFolderID: "r2", Size: 6, MD5Checksum: "m1"},
+	}
+
+	kept, dropped := DedupeFiles(files, false)
+	if dropped != 1 || len(kept) != 4 || kept[0].Path != "first" {
+		t.Errorf("by ID: kept %v, dropped %d; want a (first), b, c, d and 1 dropped", kept, dropped)
+	}
+
+	kept, dropped = DedupeFiles(files, true)
+	var ids []string
+	for _, f := range kept {
+		ids = append(ids, f.ID)
+	}
+	if dropped != 2 || strings.Join(ids, ",") != "a,b,d" {
+		t.Errorf("by content: kept %v, dropped %d; want a,b,d and 2 dropped", ids, dropped)
+	}
+}
+
+func TestGlobs(t *testing.T) {
+	if _, err := ParseGlobs("*.jpg,[a-"); err == nil {
+		t.Error("ParseGlobs accepted a malformed pattern")
+	}
+	globs, err := ParseGlobs(" *.jpg , IMG_*,")
+	if err != nil || len(globs) != 2 {
+		t.Fatalf("ParseGlobs = %v, %v; want two patterns", globs, err)
+	}
+
+	files := []DriveFile{{Name: "beach.JPG"}, {Name: "IMG_0001.png"}, {Name: "notes.txt", Path: "x.jpg"}}
+	var names []string
+	for _, f := range FilterFilesByGlob(files, globs) {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "beach.JPG,IMG_0001.png" {
+		t.Errorf("FilterFilesByGlob kept %v, want beach.JPG and IMG_0001.png", names)
+	}
+	if got := FilterFiles(files, []string{"notes", GlobPrefix + "*.png"}); len(got) != 2 {
+		t.Errorf("FilterFiles with a glob term kept %v, want notes.txt and IMG_0001.png", got)
+	}
+}
+
+func TestMimeFilter(t *testing.T) {
+	if _, err := ParseMimeTypes("image/jpeg\nnot a type\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ParseMimeTypes error = %v, want one for line 2", err)
+	}
+	allow, err := ParseMimeTypes("# photos\nimage/*\n\nVideo/MP4\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := MimeFilter{Allow: allow, Deny: []string{"image/gif"}}
+
+	for mimeType, want := range map[string]bool{
+		"image/jpeg": true,
+		"image/gif":  false,
+		"video/mp4":  true,
+		"text/plain": false,
+	} {
+		if got := f.Match(mimeType); got != want {
+			t.Errorf("Match(%q) = %v, want %v", mimeType, got, want)
+		}
+	}
+	if got := (MimeFilter{Deny: []string{"text/plain"}}).Match("image/png"); !got {
+		t.Error("a deny list alone dropped a type it doesn't list")
+	}
+}
+
+func TestCorruptToken(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+	file := filepath.Join(t.TempDir(), "token.json")
+	if err := os.WriteFile(file, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	scopes := []string{"scope"}
+
+	if _, err := loadToken(file, scopes); !errors.Is(err, errCorruptToken) {
+		t.Fatalf("loadToken error = %v, want errCorruptToken", err)
+	}
+	if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("corrupt token file still there: %v", err)
+	}
+
+	if err := saveToken(file, &oauth2.Token{AccessToken: "new"}, scopes); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := loadToken(file, scopes)
+	if err != nil || tok.AccessToken != "new" {
+		t.Errorf("loadToken after re-authorizing = %v, %v, want the new token", tok, err)
+	}
+}
+
+func TestListFolderLoop(t *testing.T) {
+	folder := func(id, name string) *drive.File {
+		return &drive.File{Id: id, Name: name, MimeType: "application/vnd.google-apps.folder"}
+	}
+	// b lists its ancestor a again, and c appears under both a and b
+	fake := &fakeFiles{children: map[string][]*drive.File{
+		"root": {folder("a", "A")},
+		"a":    {folder("b", "B"), folder("c", "C"), {Id: "f1", Name: "one.txt", Size: 1}},
+		"b":    {folder("a", "A again"), folder("c", "C again")},
+		"c":    {{Id: "f2", Name: "two.txt", Size: 2}},
+	}}
+	c := &Client{service: fake}
+
+	files, err := c.listFolderTree(context.Background(), "root", 20, nil)
+	if err == nil || !strings.Contains(err.Error(), "already listed") {
+		t.Errorf("listFolderTree error = %v, want a warning about folders already listed", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("listed %d files, want 2: %v", len(files), files)
+	}
+	if fake.lists != 4 {
+		t.Errorf("made %d list calls, want one per folder (4)", fake.lists)
+	}
+}
@@ -0,0 +1,60 @@
+package drive
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// connLimiter caps how many requests a client has open at once, whether
+// they list folders, fetch metadata or download content. A request holds its
+// slot until its response body is closed, so a download keeps it for as long
+// as it streams.
+type connLimiter struct {
+	base  http.RoundTripper
+	slots chan struct{}
+}
+
+// limitConnections returns hc with its requests limited to n open at once,
+// or hc itself if n <= 0
+func limitConnections(hc *http.Client, n int) *http.Client {
+	if n <= 0 {
+		return hc
+	}
+	base := hc.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited := *hc
+	limited.Transport = &connLimiter{base: base, slots: make(chan struct{}, n)}
+	return &limited
+}
+
+// RoundTrip waits for a free slot, or for the request's context to end
+func (l *connLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case l.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := l.base.RoundTrip(req)
+	if err != nil {
+		<-l.slots
+		return nil, err
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, release: func() { <-l.slots }}
+	return resp, nil
+}
+
+// slotBody gives back its request's slot when it is closed
+type slotBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,49 @@
+package drive
+
+// DedupeFiles drops files listed more than once, as happens when links
+// overlap: repeats of an ID and, with byContent, files with the same size and
+// MD5 as one found under another link. The first occurrence is kept, with its
+// path. It returns the kept files and how many were dropped.
+func DedupeFiles(files []DriveFile, byContent bool) ([]DriveFile, int) {
+	type content struct {
+		size int64
+		md5  string
+	}
+	seenIDs := make(map[string]bool, len(files))
+	firstRoot := make(map[content]string)
+
+	kept := make([]DriveFile, 0, len(files))
+	for _, f := range files {
+		if seenIDs[f.ID] {
+			continue
+		}
+		seenIDs[f.ID] = true
+
+		if byContent && f.MD5Checksum != "" {
+			key := content{f.Size, f.MD5Checksum}
+			root, seen := firstRoot[key]
+			if seen && root != f.RootFolderID {
+				// Copies within one link are kept, they were put there on purpose
+				continue
+			}
+			if !seen {
+				firstRoot[key] = f.RootFolderID
+			}
+		}
+		kept = append(kept, f)
+	}
+	return kept, len(files) - len(kept)
+}
+
+// SetDedupeByContent makes listings of several folders also drop files with
+// the same size and MD5 as a file found under an earlier link, on top of
+// files listed twice by ID (see DedupeFiles).
+func (c *Client) SetDedupeByContent(on bool) {
+	c.dedupeContent = on
+}
+
+// CollapsedDuplicates returns how many duplicate files the last
+// ListFilesFromFolders call dropped.
+func (c *Client) CollapsedDuplicates() int {
+	return int(c.duplicates.Load())
+}
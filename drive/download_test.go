@@ -0,0 +1,422 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// fakeFiles serves file contents from memory in place of the Drive API.
+// Revisions are keyed by file ID; their content by "fileID@revisionID".
+// Listings are children keyed by folder ID; Get serves metadata by file ID.
+type fakeFiles struct {
+	content   map[string][]byte
+	revisions map[string][]*drive.Revision
+	children  map[string][]*drive.File
+	metadata  map[string]*drive.File
+	downloads int
+	lists     int
+	gets      int
+}
+
+func (f *fakeFiles) Get(ctx context.Context, fileID, fields string) (*drive.File, error) {
+	f.gets++
+	if meta, ok := f.metadata[fileID]; ok {
+		return meta, nil
+	}
+	return nil, &googleapi.Error{Code: http.StatusNotFound}
+}
+
+func (f *fakeFiles) List(ctx context.Context, query, fields, pageToken string, pageSize int64) (*drive.FileList, error) {
+	f.lists++
+	folderID, _, _ := strings.Cut(strings.TrimPrefix(query, "'"), "'")
+	return &drive.FileList{Files: f.children[folderID]}, nil
+}
+
+func (f *fakeFiles) Download(ctx context.Context, fileID string, header http.Header) (*http.Response, error) {
+	f.downloads++
+	body, ok := f.content[fileID]
+	if !ok {
+		return nil, &googleapi.Error{Code: http.StatusNotFound}
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func (f *fakeFiles) Export(ctx context.Context, fileID, mimeType string) (*http.Response, error) {
+	return f.Download(ctx, fileID, nil)
+}
+
+func (f *fakeFiles) Revisions(ctx context.Context, fileID, fields, pageToken string) (*drive.RevisionList, error) {
+	revisions, ok := f.revisions[fileID]
+	if !ok {
+		return nil, &googleapi.Error{Code: http.StatusForbidden}
+	}
+	return &drive.RevisionList{Revisions: revisions}, nil
+}
+
+func (f *fakeFiles) DownloadRevision(ctx context.Context, fileID, revisionID string) (*http.Response, error) {
+	return f.Download(ctx, fileID+"@"+revisionID, nil)
+}
+
+// downloadWithProgress runs DownloadFile and returns everything it sent on the progress channel
+func downloadWithProgress(c *Client, file DriveFile, destDir string) ([]DownloadProgress, error) {
+	progress := make(chan DownloadProgress, 100)
+	err := c.DownloadFile(context.Background(), file, destDir, progress)
+	close(progress)
+	var sent []DownloadProgress
+	for p := range progress {
+		sent = append(sent, p)
+	}
+	return sent, err
+}
+
+func TestDownloadFile(t *testing.T) {
+	content := []byte("hello, drive")
+	file := DriveFile{ID: "f1", Name: "hello.txt", Size: int64(len(content))}
+
+	t.Run("new file", func(t *testing.T) {
+		fake := &fakeFiles{content: map[string][]byte{"f1": content}}
+		c := &Client{service: fake}
+		dir := t.TempDir()
+
+		sent, err := downloadWithProgress(c, file, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dir, "hello.txt"))
+		if err != nil || !bytes.Equal(got, content) {
+			t.Errorf("saved %q, %v; want %q", got, err, content)
+		}
+		if len(sent) == 0 {
+			t.Fatal("no progress sent")
+		}
+		last := sent[len(sent)-1]
+		if !last.Done || last.Skipped || last.BytesLoaded != file.Size || last.LocalPath != filepath.Join(dir, "hello.txt") {
+			t.Errorf("final progress = %+v, want done with all bytes and the local path", last)
+		}
+	})
+
+	t.Run("skip on matching size", func(t *testing.T) {
+		fake := &fakeFiles{content: map[string][]byte{"f1": content}}
+		c := &Client{service: fake}
+		dir := t.TempDir()
+		existing := bytes.Repeat([]byte("x"), len(content))
+		os.WriteFile(filepath.Join(dir, "hello.txt"), existing, 0o644)
+
+		sent, err := downloadWithProgress(c, file, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fake.downloads != 0 {
+			t.Errorf("downloaded %d times, want the existing copy kept", fake.downloads)
+		}
+		if len(sent) != 1 || !sent[0].Skipped || !sent[0].Done {
+			t.Errorf("progress = %+v, want a single skipped update", sent)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dir, "hello.txt")); !bytes.Equal(got, existing) {
+			t.Errorf("existing copy was replaced with %q", got)
+		}
+	})
+
+	t.Run("skip exported file saved since its last change", func(t *testing.T) {
+		doc := DriveFile{ID: "d1", Name: "notes", MimeType: mimeDocument, ModifiedTime: time.Now().Add(-time.Hour)}
+		fake := &fakeFiles{content: map[string][]byte{"d1": content}}
+		c := &Client{service: fake}
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "notes.docx"), []byte("exported"), 0o644)
+
+		if _, err := downloadWithProgress(c, doc, dir); err != nil {
+			t.Fatal(err)
+		}
+		if fake.downloads != 0 {
+			t.Errorf("exported %d times, want the existing copy kept", fake.downloads)
+		}
+
+		// Edited on Drive after the copy was saved
+		doc.ModifiedTime = time.Now().Add(time.Hour)
+		if _, err := downloadWithProgress(c, doc, dir); err != nil {
+			t.Fatal(err)
+		}
+		if fake.downloads != 1 {
+			t.Errorf("exported %d times, want the outdated copy replaced", fake.downloads)
+		}
+	})
+
+	t.Run("re-download on size mismatch", func(t *testing.T) {
+		fake := &fakeFiles{content: map[string][]byte{"f1": content}}
+		c := &Client{service: fake}
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("stale"), 0o644)
+
+		if _, err := downloadWithProgress(c, file, dir); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dir, "hello.txt")); !bytes.Equal(got, content) {
+			t.Errorf("saved %q, want the stale copy replaced with %q", got, content)
+		}
+	})
+
+	t.Run("recheck size before re-downloading", func(t *testing.T) {
+		for _, current := range []int64{5, file.Size} {
+			fake := &fakeFiles{
+				content:  map[string][]byte{"f1": content},
+				metadata: map[string]*drive.File{"f1": {Size: current}},
+			}
+			c := &Client{service: fake}
+			c.SetRecheckSize(true)
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("stale"), 0o644)
+
+			if _, err := downloadWithProgress(c, file, dir); err != nil {
+				t.Fatal(err)
+			}
+			// The 5-byte local copy is kept if Drive now reports 5 bytes
+			if want := current == file.Size; fake.gets != 1 || (fake.downloads == 1) != want {
+				t.Errorf("current size %d: %d gets, %d downloads", current, fake.gets, fake.downloads)
+			}
+		}
+	})
+
+	t.Run("overwrite asks before replacing a changed file", func(t *testing.T) {
+		for _, confirm := range []bool{false, true} {
+			fake := &fakeFiles{content: map[string][]byte{"f1": content}}
+			c := &Client{service: fake, existPolicy: ExistOverwrite}
+			asked := 0
+			c.SetOverwriteConfirm(func(ctx context.Context, f DriveFile, localPath string) bool {
+				asked++
+				return confirm
+			})
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("edited"), 0o644)
+
+			sent, err := downloadWithProgress(c, file, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(filepath.Join(dir, "hello.txt"))
+			if asked != 1 || bytes.Equal(got, content) != confirm || sent[len(sent)-1].Skipped == confirm {
+				t.Errorf("confirm %v: asked %d times, saved %q, final progress %+v", confirm, asked, got, sent[len(sent)-1])
+			}
+		}
+	})
+
+	t.Run("subdirectories", func(t *testing.T) {
+		fake := &fakeFiles{content: map[string][]byte{"f1": content}}
+		c := &Client{service: fake}
+		dir := t.TempDir()
+		nested := file
+		nested.Path = "a/b"
+
+		if _, err := downloadWithProgress(c, nested, dir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "a", "b", "hello.txt")); err != nil {
+			t.Errorf("nested file not saved: %v", err)
+		}
+	})
+
+	t.Run("unknown size is never segmented", func(t *testing.T) {
+		fake := &fakeFiles{content: map[string][]byte{"f1": content}}
+		c := &Client{service: fake, segments: 4}
+		if err := c.SetSegmentedDownload(4, 0); err == nil {
+			t.Error("SetSegmentedDownload accepted a threshold of 0")
+		}
+		dir := t.TempDir()
+		unsized := file
+		unsized.Size = 0
+
+		if _, err := downloadWithProgress(c, unsized, dir); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dir, "hello.txt")); !bytes.Equal(got, content) {
+			t.Errorf("saved %q, want %q", got, content)
+		}
+	})
+
+	t.Run("output structure folders for a root-level file", func(t *testing.T) {
+		dated := file
+		dated.ModifiedTime = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		dated.MimeType = "text/plain"
+		for structure, want := range map[OutputStructure]string{
+			StructureDate: filepath.Join("2024", "03", "hello.txt"),
+			StructureType: filepath.Join(typeFolder(dated.MimeType), "hello.txt"),
+		} {
+			fake := &fakeFiles{content: map[string][]byte{"f1": content}}
+			c := &Client{service: fake}
+			c.SetOutputStructure(structure)
+			dir := t.TempDir()
+
+			if _, err := downloadWithProgress(c, dated, dir); err != nil {
+				t.Fatalf("%s: %v", structure, err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, want)); err != nil {
+				t.Errorf("%s: file not saved at %s: %v", structure, want, err)
+			}
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c := &Client{service: &fakeFiles{}}
+		dir := t.TempDir()
+
+		_, err := downloadWithProgress(c, file, dir)
+		var gerr *googleapi.Error
+		if !errors.As(err, &gerr) || gerr.Code != http.StatusNotFound {
+			t.Fatalf("DownloadFile error = %v, want the 404", err)
+		}
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 0 {
+			t.Errorf("left %d files behind after a failed download", len(entries))
+		}
+	})
+}
+
+// TestDestPathMatchesDownload checks that the path the TUI looks for local
+// copies at is where DownloadFile saves, for the cases where the two used to
+// disagree: exported Workspace files, renamed files, and names stored under
+// another Unicode normalization.
+func TestDestPathMatchesDownload(t *testing.T) {
+	names, err := ParseNameTemplate(`{{.ModifiedTime.Format "2006"}}-{{.Name}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("data")
+	files := []DriveFile{
+		{ID: "doc", Name: "Notes", MimeType: mimeDocument},
+		{ID: "plain", Name: "a.txt", Path: "sub", Size: int64(len(content))},
+		{ID: "accent", Name: "café.txt", Size: int64(len(content)), ModifiedTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, templated := range []bool{false, true} {
+		fake := &fakeFiles{content: map[string][]byte{"doc": content, "plain": content, "accent": content}}
+		c := &Client{service: fake}
+		if templated {
+			c.SetNameTemplate(names)
+		}
+		dir := t.TempDir()
+		for _, f := range files {
+			if _, err := downloadWithProgress(c, f, dir); err != nil {
+				t.Fatal(err)
+			}
+			want, err := c.DestPath(dir, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(want); err != nil {
+				t.Errorf("templated=%v: %s not saved at DestPath %s", templated, f.Name, want)
+			}
+		}
+
+		// A copy saved decomposed is found, and kept, under its own name
+		nfdDir := t.TempDir()
+		saved, _ := c.DestPath(nfdDir, files[2])
+		nfd := filepath.Join(nfdDir, norm.NFD.String(filepath.Base(saved)))
+		os.WriteFile(nfd, content, 0o644)
+		got, err := c.DestPath(nfdDir, files[2])
+		if err != nil || got != nfd {
+			t.Errorf("templated=%v: DestPath = %q, %v; want the decomposed copy %q", templated, got, err, nfd)
+		}
+		fake.downloads = 0
+		if _, err := downloadWithProgress(c, files[2], nfdDir); err != nil || fake.downloads != 0 {
+			t.Errorf("templated=%v: decomposed copy downloaded again (%d, %v)", templated, fake.downloads, err)
+		}
+	}
+}
+
+func TestDownloadRevision(t *testing.T) {
+	fake := &fakeFiles{
+		content: map[string][]byte{"f1": []byte("current"), "f1@r1": []byte("first")},
+		revisions: map[string][]*drive.Revision{"f1": {
+			{Id: "r1", Size: 5, ModifiedTime: "2024-01-02T03:04:05Z"},
+			{Id: "r2", Size: 7},
+		}},
+	}
+	c := &Client{service: fake}
+	file := DriveFile{ID: "f1", Name: "notes.txt", Size: 7}
+
+	revisions, err := c.ListRevisions(context.Background(), file)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("ListRevisions = %v, %v, want 2 revisions", revisions, err)
+	}
+	for spec, want := range map[string]string{"1": "r1", "-1": "r2", "r2": "r2"} {
+		if r, err := PickRevision(revisions, spec); err != nil || r.ID != want {
+			t.Errorf("PickRevision(%q) = %v, %v, want %s", spec, r.ID, err, want)
+		}
+	}
+	for _, spec := range []string{"0", "3", "-3", "nope"} {
+		if _, err := PickRevision(revisions, spec); err == nil {
+			t.Errorf("PickRevision(%q) succeeded, want an error", spec)
+		}
+	}
+
+	dir := t.TempDir()
+	old := file.AtRevision(revisions[0])
+	if err := c.DownloadFile(context.Background(), old, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	saved := filepath.Join(dir, "notes (rev 2024-01-02 030405).txt")
+	if got, _ := os.ReadFile(saved); string(got) != "first" {
+		t.Errorf("downloaded %q, want the first revision", got)
+	}
+
+	// Neither the current version nor an earlier copy of the revision, even
+	// one the same size, stops it from being fetched
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("current"), 0o644)
+	os.WriteFile(saved, []byte("stale"), 0o644)
+	if err := c.DownloadFile(context.Background(), old, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(saved); string(got) != "first" {
+		t.Errorf("revision copy is %q, want it fetched again", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "notes.txt")); string(got) != "current" {
+		t.Errorf("current version replaced with %q", got)
+	}
+	if r := file.AtRevision(Revision{ID: "r9"}); r.Name != "notes (rev r9).txt" {
+		t.Errorf("revision without a date named %q", r.Name)
+	}
+
+	if _, err := c.ListRevisions(context.Background(), DriveFile{ID: "f2"}); !errors.Is(err, ErrNoRevisions) {
+		t.Errorf("ListRevisions without access = %v, want ErrNoRevisions", err)
+	}
+	if _, err := c.ListRevisions(context.Background(), DriveFile{ID: "f1", MimeType: mimeDocument}); !errors.Is(err, ErrNoRevisions) {
+		t.Errorf("ListRevisions of a Google Doc = %v, want ErrNoRevisions", err)
+	}
+}
+
+func TestProgressThrottled(t *testing.T) {
+	const size = 10000
+	file := DriveFile{ID: "big", Name: "big.bin", Size: size}
+	progress := make(chan DownloadProgress, 100)
+	c := &Client{}
+	var sink bytes.Buffer
+	err := c.copyWithProgress(file, iotest.OneByteReader(bytes.NewReader(make([]byte, size))), &sink, progress)
+	close(progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []DownloadProgress
+	for p := range progress {
+		sent = append(sent, p)
+	}
+	// One read per byte, but updates only every progressInterval plus the last
+	if len(sent) == 0 || len(sent) > 10 {
+		t.Fatalf("sent %d updates for %d reads, want a few", len(sent), size)
+	}
+	if last := sent[len(sent)-1]; last.BytesLoaded != size {
+		t.Errorf("last update has %d bytes, want %d", last.BytesLoaded, size)
+	}
+}
@@ -0,0 +1,178 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// permissionReasons are googleapi error reasons that mean the caller can't see a file.
+var permissionReasons = map[string]bool{
+	"insufficientFilePermissions": true,
+	"insufficientPermissions":     true,
+	"forbidden":                   true,
+	"appNotAuthorizedToFile":      true,
+}
+
+// errorBody is the JSON of a Drive error response
+type errorBody struct {
+	Error struct {
+		Message string                `json:"message"`
+		Errors  []googleapi.ErrorItem `json:"errors"`
+	} `json:"error"`
+}
+
+// parseErrorBody decodes the response body of gerr. Failed downloads leave
+// it unparsed, so their reason and message are only found there.
+func parseErrorBody(gerr *googleapi.Error) errorBody {
+	var body errorBody
+	json.Unmarshal([]byte(gerr.Body), &body)
+	return body
+}
+
+// errorReason returns the first reason reported in a googleapi error, or ""
+func errorReason(gerr *googleapi.Error) string {
+	if len(gerr.Errors) > 0 {
+		return gerr.Errors[0].Reason
+	}
+	if body := parseErrorBody(gerr); len(body.Error.Errors) > 0 {
+		return body.Error.Errors[0].Reason
+	}
+	return ""
+}
+
+// ErrorCategory groups failures by what the user can do about them.
+type ErrorCategory string
+
+const (
+	// ErrorPermission means the caller may not read the file
+	ErrorPermission ErrorCategory = "permission"
+	// ErrorQuota means Drive refused for now: rate limits or download quotas
+	ErrorQuota ErrorCategory = "quota"
+	// ErrorNotFound means the file is gone or not visible to the caller
+	ErrorNotFound ErrorCategory = "not found"
+	// ErrorNetwork means no response, or a connection that broke mid-transfer
+	ErrorNetwork ErrorCategory = "network"
+	// ErrorOther is anything else, e.g. a full disk
+	ErrorOther ErrorCategory = "other"
+)
+
+// quotaReasons are 403 reasons that mean a quota rather than a lack of access
+var quotaReasons = map[string]bool{
+	"rateLimitExceeded":        true,
+	"userRateLimitExceeded":    true,
+	"dailyLimitExceeded":       true,
+	"quotaExceeded":            true,
+	"downloadQuotaExceeded":    true,
+	"sharingRateLimitExceeded": true,
+}
+
+// apiKeyQuotaReasons are the quota reasons a client using an API key runs
+// into. All its requests count against the key's Cloud project rather than
+// a user, so it hits these far sooner than a signed-in account.
+var apiKeyQuotaReasons = map[string]bool{
+	"dailyLimitExceeded":    true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+}
+
+// APIKeyQuotaAdvice explains why an API key ran out of quota and what to do.
+const APIKeyQuotaAdvice = "every request made with an API key counts against the quota of the key's project, " +
+	"which has per-minute and daily limits (the daily one resets at midnight Pacific time); " +
+	"sign in with OAuth (-oauth) so requests count against your own account instead"
+
+// IsAPIKeyQuota reports whether err is a quota error that a client
+// authenticating with an API key ran into, as explained by APIKeyQuotaAdvice.
+func (c *Client) IsAPIKeyQuota(err error) bool {
+	var gerr *googleapi.Error
+	return c != nil && c.apiKey && errors.As(err, &gerr) && apiKeyQuotaReasons[errorReason(gerr)]
+}
+
+// WithQuotaAdvice adds APIKeyQuotaAdvice to err if IsAPIKeyQuota, and
+// otherwise returns err as it is.
+func (c *Client) WithQuotaAdvice(err error) error {
+	if !c.IsAPIKeyQuota(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, APIKeyQuotaAdvice)
+}
+
+// CategorizeError returns the ErrorCategory of a download failure.
+func CategorizeError(err error) ErrorCategory {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		reason := errorReason(gerr)
+		switch {
+		case gerr.Code == http.StatusTooManyRequests || quotaReasons[reason]:
+			return ErrorQuota
+		case gerr.Code == http.StatusNotFound || reason == "notFound":
+			return ErrorNotFound
+		case gerr.Code == http.StatusUnauthorized || gerr.Code == http.StatusForbidden || permissionReasons[reason]:
+			return ErrorPermission
+		case gerr.Code >= 500:
+			return ErrorNetwork
+		}
+		return ErrorOther
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorNetwork
+	}
+	return ErrorOther
+}
+
+// apiError wraps a failed API call made to do action (e.g. "download file")
+// so its message leads with the HTTP status and Drive's reason, which
+// googleapi leaves buried in a response body for downloads, followed by
+// Drive's own message.
+func apiError(action string, err error) error {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return fmt.Errorf("unable to %s: %w", action, err)
+	}
+	status := fmt.Sprintf("HTTP %d", gerr.Code)
+	if reason := errorReason(gerr); reason != "" {
+		status += " " + reason
+	}
+	message := gerr.Message
+	if message == "" {
+		message = parseErrorBody(gerr).Error.Message
+	}
+	if message == "" {
+		return fmt.Errorf("unable to %s (%s): %w", action, status, err)
+	}
+	return &detailedError{msg: fmt.Sprintf("unable to %s (%s): %s", action, status, message), err: err}
+}
+
+// detailedError replaces the message of err, which it still wraps
+type detailedError struct {
+	msg string
+	err error
+}
+
+func (e *detailedError) Error() string { return e.msg }
+func (e *detailedError) Unwrap() error { return e.err }
+
+// listError wraps a Files.List failure, turning permission and not-found
+// errors into an actionable message that names the folder.
+func listError(folderID string, err error) error {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return fmt.Errorf("unable to list files: %w", err)
+	}
+
+	reason := errorReason(gerr)
+	switch {
+	case gerr.Code == http.StatusNotFound || reason == "notFound":
+		return fmt.Errorf("folder %s not found: check the link, or if it is shared privately use OAuth (-oauth) with an account that has access: %w", folderID, err)
+	case gerr.Code == http.StatusUnauthorized || permissionReasons[reason]:
+		return fmt.Errorf("no permission to list folder %s: ask the owner to share it as \"Anyone with the link\", or use OAuth (-oauth) with an account that has access: %w", folderID, err)
+	}
+	return fmt.Errorf("unable to list files: %w", err)
+}
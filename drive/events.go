@@ -0,0 +1,120 @@
+package drive
+
+import (
+	"context"
+	"time"
+)
+
+// EventKind identifies what happened to a download in an Event.
+type EventKind int
+
+const (
+	// EventStarted is sent when a file's transfer begins
+	EventStarted EventKind = iota
+	// EventProgress is sent as bytes arrive, at most every 100ms or 4 MiB;
+	// BytesLoaded is the running total
+	EventProgress
+	// EventRetry is sent before a failed request for the file is retried;
+	// Attempt, Delay, and Err describe the retry
+	EventRetry
+	// EventSkipped is sent when a current local copy means nothing is downloaded
+	EventSkipped
+	// EventCompleted is sent once the file is saved at LocalPath
+	EventCompleted
+	// EventFailed is sent when the download gives up with Err, including when
+	// its context is cancelled
+	EventFailed
+)
+
+// String returns the event kind's name, e.g. "completed"
+func (k EventKind) String() string {
+	switch k {
+	case EventStarted:
+		return "started"
+	case EventProgress:
+		return "progress"
+	case EventRetry:
+		return "retry"
+	case EventSkipped:
+		return "skipped"
+	case EventCompleted:
+		return "completed"
+	case EventFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// Event describes one step of a download. Exactly one EventSkipped,
+// EventCompleted, or EventFailed ends every DownloadFile call.
+type Event struct {
+	Kind EventKind
+	File DriveFile
+	// BytesLoaded and TotalBytes report progress; TotalBytes is 0 when the
+	// size is unknown
+	BytesLoaded int64
+	TotalBytes  int64
+	// LocalPath is where the file was saved or the existing copy that was kept
+	LocalPath string
+	// Attempt is the 1-based number of the attempt that failed, for EventRetry
+	Attempt int
+	// Delay is how long the retry waits, for EventRetry
+	Delay time.Duration
+	// Err is the error for EventRetry and EventFailed
+	Err error
+}
+
+// EventHandler receives download events. It is called from the downloading
+// goroutines, possibly concurrently, so it must be safe for that and return
+// quickly.
+type EventHandler func(Event)
+
+// SetEventHandler registers h to receive download events, alongside any
+// progress channel passed to DownloadFile. Set it before starting downloads;
+// nil removes it.
+func (c *Client) SetEventHandler(h EventHandler) {
+	c.events = h
+}
+
+// emit sends e to the event handler, if there is one
+func (c *Client) emit(e Event) {
+	if c.events != nil {
+		c.events(e)
+	}
+}
+
+// eventFileKey is the context key under which DownloadFile records the file
+// being fetched, so retries deep in withRetry can be reported against it
+type eventFileKey struct{}
+
+// retryProgressKey is the context key under which DownloadFile records its
+// progress channel, so withRetry can report retries on it
+type retryProgressKey struct{}
+
+// contextWithEventFile returns ctx tagged with the file being downloaded
+func contextWithEventFile(ctx context.Context, file DriveFile) context.Context {
+	return context.WithValue(ctx, eventFileKey{}, file)
+}
+
+// contextWithDownload returns ctx tagged with the file being downloaded and
+// the channel its progress goes to, which may be nil
+func contextWithDownload(ctx context.Context, file DriveFile, progressChan chan<- DownloadProgress) context.Context {
+	ctx = contextWithEventFile(ctx, file)
+	if progressChan != nil {
+		ctx = context.WithValue(ctx, retryProgressKey{}, progressChan)
+	}
+	return ctx
+}
+
+// retryProgressFrom returns the progress channel ctx was tagged with by
+// contextWithDownload, or nil
+func retryProgressFrom(ctx context.Context) chan<- DownloadProgress {
+	progressChan, _ := ctx.Value(retryProgressKey{}).(chan<- DownloadProgress)
+	return progressChan
+}
+
+// eventFileFrom returns the file ctx was tagged with by contextWithEventFile
+func eventFileFrom(ctx context.Context) (DriveFile, bool) {
+	file, ok := ctx.Value(eventFileKey{}).(DriveFile)
+	return file, ok
+}
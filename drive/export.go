@@ -0,0 +1,138 @@
+package drive
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Google Workspace MIME types that can be exported
+const (
+	mimeDocument     = "application/vnd.google-apps.document"
+	mimeSpreadsheet  = "application/vnd.google-apps.spreadsheet"
+	mimePresentation = "application/vnd.google-apps.presentation"
+	mimeDrawing      = "application/vnd.google-apps.drawing"
+)
+
+// exportTypes maps the short type names accepted by ParseExportMap to Workspace MIME types
+var exportTypes = map[string]string{
+	"document":     mimeDocument,
+	"spreadsheet":  mimeSpreadsheet,
+	"presentation": mimePresentation,
+	"drawing":      mimeDrawing,
+}
+
+// exportFormats lists, per Workspace type, the formats Drive can export it
+// as, keyed by file extension
+var exportFormats = map[string]map[string]string{
+	mimeDocument: {
+		"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"odt":  "application/vnd.oasis.opendocument.text",
+		"rtf":  "application/rtf",
+		"pdf":  "application/pdf",
+		"txt":  "text/plain",
+		"epub": "application/epub+zip",
+		"md":   "text/markdown",
+	},
+	mimeSpreadsheet: {
+		"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"ods":  "application/vnd.oasis.opendocument.spreadsheet",
+		"pdf":  "application/pdf",
+		"csv":  "text/csv",
+		"tsv":  "text/tab-separated-values",
+	},
+	mimePresentation: {
+		"pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"odp":  "application/vnd.oasis.opendocument.presentation",
+		"pdf":  "application/pdf",
+		"txt":  "text/plain",
+	},
+	mimeDrawing: {
+		"pdf": "application/pdf",
+		"png": "image/png",
+		"jpg": "image/jpeg",
+		"svg": "image/svg+xml",
+	},
+}
+
+// ExportMap chooses the format each Google Workspace type is exported as,
+// mapping its MIME type to a file extension from exportFormats.
+type ExportMap map[string]string
+
+// DefaultExportMap returns the formats used unless overridden: Office
+// formats for documents, spreadsheets and presentations, PDF for drawings.
+func DefaultExportMap() ExportMap {
+	return ExportMap{
+		mimeDocument:     "docx",
+		mimeSpreadsheet:  "xlsx",
+		mimePresentation: "pptx",
+		mimeDrawing:      "pdf",
+	}
+}
+
+// ParseExportMap parses comma-separated type=format pairs such as
+// "document=pdf,spreadsheet=csv" on top of DefaultExportMap. Types are
+// document, spreadsheet, presentation and drawing; formats are the file
+// extensions Drive can export that type as.
+func ParseExportMap(value string) (ExportMap, error) {
+	m := DefaultExportMap()
+	for pair := range strings.SplitSeq(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, format, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not type=format", pair)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+
+		mimeType, ok := exportTypes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q (want one of %s)", name, strings.Join(slices.Sorted(maps.Keys(exportTypes)), ", "))
+		}
+		if _, ok := exportFormats[mimeType][format]; !ok {
+			return nil, fmt.Errorf("%s can't be exported as %q (want one of %s)", name, format, strings.Join(slices.Sorted(maps.Keys(exportFormats[mimeType])), ", "))
+		}
+		m[mimeType] = format
+	}
+	return m, nil
+}
+
+// SetExportMap sets the formats Google Workspace files are exported as.
+// A nil map restores DefaultExportMap.
+func (c *Client) SetExportMap(m ExportMap) {
+	c.exports = m
+}
+
+// exportFormat returns the extension and MIME type file is exported as, or
+// ok=false if file is not an exportable Workspace file
+func (c *Client) exportFormat(file DriveFile) (ext, mimeType string, ok bool) {
+	var exports ExportMap
+	if c != nil {
+		exports = c.exports
+	}
+	if exports == nil {
+		exports = DefaultExportMap()
+	}
+	ext, ok = exports[file.MimeType]
+	if !ok {
+		return "", "", false
+	}
+	mimeType, ok = exportFormats[file.MimeType][ext]
+	return ext, mimeType, ok
+}
+
+// ExportedFile returns file as it is saved locally: a Google Workspace file
+// gets the extension of the format it is exported as, anything else is
+// returned unchanged. A nil Client assumes DefaultExportMap.
+func (c *Client) ExportedFile(file DriveFile) DriveFile {
+	ext, _, ok := c.exportFormat(file)
+	if ok && !strings.EqualFold(strings.TrimPrefix(path.Ext(file.Name), "."), ext) {
+		file.Name += "." + ext
+	}
+	return file
+}
@@ -0,0 +1,54 @@
+package drive
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// GlobPrefix marks a search term as a shell glob matched against file names,
+// e.g. "glob:IMG_*.jpg", instead of a substring.
+const GlobPrefix = "glob:"
+
+// ParseGlobs splits comma-separated glob patterns such as "*.jpg,IMG_*",
+// reporting the first malformed one.
+func ParseGlobs(value string) ([]string, error) {
+	var globs []string
+	for pattern := range strings.SplitSeq(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+		globs = append(globs, pattern)
+	}
+	return globs, nil
+}
+
+// MatchGlob reports whether name matches the shell glob pattern, ignoring
+// case as searches do. A malformed pattern matches nothing.
+func MatchGlob(pattern, name string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && ok
+}
+
+// FilterFilesByGlob keeps files whose name matches any of globs (OR logic).
+// No globs keeps every file.
+func FilterFilesByGlob(files []DriveFile, globs []string) []DriveFile {
+	if len(globs) == 0 {
+		return files
+	}
+
+	var filtered []DriveFile
+	for _, f := range files {
+		for _, g := range globs {
+			if MatchGlob(g, f.Name) {
+				filtered = append(filtered, f)
+				break
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,124 @@
+package drive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestName is the file UpdateManifest keeps in the download directory
+const ManifestName = "manifest.json"
+
+// Manifest is a durable index of the files downloaded into a directory,
+// accumulated across runs.
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry records one downloaded file.
+type ManifestEntry struct {
+	// ID is the Google Drive file identifier
+	ID string `json:"id"`
+	// Path is the file's path on Drive, relative to the listed folder
+	Path string `json:"path"`
+	// LocalPath is where the file was saved, relative to the directory
+	// holding the manifest, with forward slashes
+	LocalPath string `json:"local_path"`
+	Size      int64  `json:"size"`
+	// MD5Checksum is Drive's checksum for the file, empty when it has none
+	MD5Checksum  string    `json:"md5_checksum,omitempty"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// NewManifestEntry describes file as saved at localPath inside destDir
+func NewManifestEntry(destDir string, file DriveFile, localPath string, downloadedAt time.Time) ManifestEntry {
+	rel, err := filepath.Rel(destDir, localPath)
+	if err != nil {
+		rel = localPath
+	}
+	return ManifestEntry{
+		ID:           file.ID,
+		Path:         file.DisplayName(),
+		LocalPath:    filepath.ToSlash(rel),
+		Size:         file.Size,
+		MD5Checksum:  file.MD5Checksum,
+		DownloadedAt: downloadedAt,
+	}
+}
+
+// UpdateManifest merges entries into destDir's manifest, creating it if
+// needed. An entry replaces any earlier one with the same ID, so re-downloads
+// update the record instead of duplicating it.
+func UpdateManifest(destDir string, entries []ManifestEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	path := filepath.Join(destDir, ManifestName)
+
+	var manifest Manifest
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("unable to read manifest: %w", err)
+	default:
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("unable to parse manifest %s: %w", path, err)
+		}
+	}
+
+	byID := make(map[string]ManifestEntry, len(manifest.Files)+len(entries))
+	for _, e := range manifest.Files {
+		byID[e.ID] = e
+	}
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	manifest.Files = make([]ManifestEntry, 0, len(byID))
+	for _, e := range byID {
+		manifest.Files = append(manifest.Files, e)
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		a, b := manifest.Files[i], manifest.Files[j]
+		if a.LocalPath != b.LocalPath {
+			return a.LocalPath < b.LocalPath
+		}
+		return a.ID < b.ID
+	})
+
+	data, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode manifest: %w", err)
+	}
+
+	// Write beside the manifest and rename, so an interrupted run never
+	// leaves a truncated index
+	tmp, err := os.CreateTemp(destDir, ManifestName+".*")
+	if err != nil {
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package drive
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MimeFilter keeps or drops files by MIME type. Entries are exact types or
+// a whole family such as "image/*", matched ignoring case.
+type MimeFilter struct {
+	// Allow, if not empty, keeps only files matching one of its types
+	Allow []string
+	// Deny drops files matching any of its types, even allowed ones
+	Deny []string
+}
+
+// ParseMimeTypes reads MIME types from a list with one per line, as in a
+// -mime-allow-file. Blank lines and lines starting with # are ignored, as in
+// a links file; any other line that isn't type/subtype is an error.
+func ParseMimeTypes(content string) ([]string, error) {
+	var types []string
+	for n, line := range listLines(content) {
+		major, minor, ok := strings.Cut(line, "/")
+		if !ok || major == "" || minor == "" || strings.ContainsAny(line, " \t") {
+			return nil, fmt.Errorf("line %d: %q is not a MIME type such as image/jpeg or image/*", n, line)
+		}
+		types = append(types, strings.ToLower(line))
+	}
+	return types, nil
+}
+
+// Match reports whether the filter keeps a file of type mimeType.
+func (f MimeFilter) Match(mimeType string) bool {
+	if matchMimeType(f.Deny, mimeType) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchMimeType(f.Allow, mimeType)
+}
+
+// Filter returns the files the filter keeps.
+func (f MimeFilter) Filter(files []DriveFile) []DriveFile {
+	if len(f.Allow) == 0 && len(f.Deny) == 0 {
+		return files
+	}
+
+	var filtered []DriveFile
+	for _, file := range files {
+		if f.Match(file.MimeType) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// matchMimeType reports whether mimeType is one of types or in one of their families
+func matchMimeType(types []string, mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	major, _, _ := strings.Cut(mimeType, "/")
+	for _, t := range types {
+		if t == mimeType || t == major+"/*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package drive
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ParseNameTemplate parses a template for the names downloaded files are
+// saved under. It is a text/template executed with the DriveFile, e.g.
+// `{{.ModifiedTime.Format "2006-01-02"}} {{.Name}}`, where .Name already has
+// the extension of any export format. The template is tried on a sample
+// file so mistakes such as unknown fields are reported before any download.
+// A template whose names would contain a path separator is refused: it can
+// only name the file, not add folders.
+func ParseNameTemplate(text string) (*template.Template, error) {
+	t, err := template.New("name").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := DriveFile{
+		ID:           "sample",
+		Name:         "sample.txt",
+		Size:         1,
+		FolderID:     "folder",
+		RootFolderID: "root",
+		MimeType:     "text/plain",
+		CreatedTime:  time.Now(),
+		ModifiedTime: time.Now(),
+	}
+	name, err := executeName(t, sample)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("names can't contain path separators, but got %q", name)
+	}
+	return t, nil
+}
+
+// SetNameTemplate sets the template downloaded files are named by. nil keeps
+// Drive's names.
+func (c *Client) SetNameTemplate(t *template.Template) {
+	c.names = t
+}
+
+// SavedFile returns file as it is saved locally: exported as by ExportedFile,
+// renamed by the client's name template, if any, and with its Path moved
+// to the folder the client's output structure puts it in, or into its name
+// under SetFlattenNames. Use it with LocalPath to find a download on disk.
+// A file the template fails for keeps its name.
+func (c *Client) SavedFile(file DriveFile) DriveFile {
+	file = c.ExportedFile(file)
+	if c == nil {
+		return file
+	}
+	if c.names != nil {
+		if name, err := executeName(c.names, file); err != nil {
+			slog.Warn("name template failed, keeping the Drive name", "file", file.DisplayName(), "err", err)
+		} else {
+			file.Name = name
+		}
+	}
+	file.Path = c.structuredPath(file)
+	if c.flattenDelim != "" {
+		file.Name = c.flattenedName(file.Path, file.Name)
+		file.Path = ""
+	}
+	return file
+}
+
+// executeName runs t for file, refusing an empty result
+func executeName(t *template.Template, file DriveFile) (string, error) {
+	var s strings.Builder
+	if err := t.Execute(&s, file); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(s.String())
+	if name == "" {
+		return "", fmt.Errorf("name template gave an empty name")
+	}
+	return name, nil
+}
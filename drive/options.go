@@ -0,0 +1,219 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*clientOptions)
+
+// clientOptions collects everything NewClient needs before it can build a Client.
+type clientOptions struct {
+	apiKey             string
+	credentialsPath    string
+	serviceAccountPath string
+	scopes             []string
+	httpClient         *http.Client
+
+	pageSize  int
+	maxDepth  int
+	retries   int
+	retryBase time.Duration
+	rateLimit float64
+	maxConns  int
+	events    EventHandler
+	batch     BatchHandler
+}
+
+// WithAPIKey authenticates with an API key. Only public files are visible.
+func WithAPIKey(apiKey string) Option {
+	return func(o *clientOptions) { o.apiKey = apiKey }
+}
+
+// WithOAuth authenticates as a user with the OAuth client in credentialsPath,
+// prompting for consent in the browser when there is no saved token for scopes.
+// Scopes default to read-only.
+func WithOAuth(credentialsPath string, scopes ...string) Option {
+	return func(o *clientOptions) {
+		o.credentialsPath = credentialsPath
+		o.scopes = scopes
+	}
+}
+
+// WithServiceAccount authenticates as the service account whose JSON key is at
+// keyPath. Scopes default to read-only.
+func WithServiceAccount(keyPath string, scopes ...string) Option {
+	return func(o *clientOptions) {
+		o.serviceAccountPath = keyPath
+		o.scopes = scopes
+	}
+}
+
+// WithHTTPClient sends all requests through hc, e.g. one from NewHTTPClient
+// to use a proxy. Without it, requests honor the proxy environment variables.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *clientOptions) { o.httpClient = hc }
+}
+
+// WithPageSize sets how many files each list request asks for (see SetPageSize).
+func WithPageSize(n int) Option {
+	return func(o *clientOptions) { o.pageSize = n }
+}
+
+// WithMaxDepth sets how deep recursive listings descend into subfolders.
+func WithMaxDepth(depth int) Option {
+	return func(o *clientOptions) { o.maxDepth = depth }
+}
+
+// WithRetries sets how many times a rate-limited or failed API call is retried.
+func WithRetries(n int) Option {
+	return func(o *clientOptions) { o.retries = n }
+}
+
+// WithRetryBaseDelay sets the backoff before the first retry (see SetRetryBaseDelay).
+func WithRetryBaseDelay(d time.Duration) Option {
+	return func(o *clientOptions) { o.retryBase = d }
+}
+
+// WithEventHandler registers h to receive download events (see SetEventHandler).
+func WithEventHandler(h EventHandler) Option {
+	return func(o *clientOptions) { o.events = h }
+}
+
+// WithBatchHandler registers h to receive overall batch progress (see SetBatchHandler).
+func WithBatchHandler(h BatchHandler) Option {
+	return func(o *clientOptions) { o.batch = h }
+}
+
+// WithRateLimit caps API calls to perSecond requests per second (0 = unlimited).
+func WithRateLimit(perSecond float64) Option {
+	return func(o *clientOptions) { o.rateLimit = perSecond }
+}
+
+// WithMaxConnections caps how many requests the client has open at once
+// across listing and downloading (0 = unlimited). A download holds its
+// connection until it finishes, and each segment of one counts separately.
+func WithMaxConnections(n int) Option {
+	return func(o *clientOptions) { o.maxConns = n }
+}
+
+// NewClient creates a Drive client configured by opts. Exactly one of
+// WithAPIKey, WithOAuth, or WithServiceAccount must be given.
+func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
+	o := clientOptions{
+		pageSize: DefaultPageSize,
+		maxDepth: DefaultMaxDepth,
+		retries:  DefaultMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	methods := 0
+	for _, set := range []bool{o.apiKey != "", o.credentialsPath != "", o.serviceAccountPath != ""} {
+		if set {
+			methods++
+		}
+	}
+	if methods != 1 {
+		return nil, fmt.Errorf("exactly one of an API key, OAuth credentials, or a service account key is required")
+	}
+	if len(o.scopes) == 0 {
+		o.scopes = []string{drive.DriveReadonlyScope}
+	}
+
+	// Every auth method builds on the same base client so proxies apply everywhere
+	if o.httpClient == nil {
+		o.httpClient = httpClientFrom(ctx)
+	}
+	ctx = contextWithHTTPClient(ctx, o.httpClient)
+
+	var hc *http.Client
+	var err error
+	switch {
+	case o.apiKey != "":
+		hc = apiKeyHTTPClient(o.httpClient, o.apiKey)
+	case o.credentialsPath != "":
+		hc, err = oauthHTTPClient(ctx, o.credentialsPath, o.scopes)
+	default:
+		hc, err = serviceAccountHTTPClient(ctx, o.serviceAccountPath, o.scopes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	hc = limitConnections(hc, o.maxConns)
+
+	srv, err := drive.NewService(ctx, option.WithHTTPClient(hc))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Drive service: %w", err)
+	}
+
+	c := &Client{
+		service:  apiFiles{srv},
+		maxDepth: o.maxDepth,
+		retries:  max(o.retries, 0),
+		limiter:  newRateLimiter(o.rateLimit),
+
+		retryBaseDelay: max(o.retryBase, 0),
+		events:         o.events,
+		batch:          o.batch,
+		apiKey:         o.apiKey != "",
+	}
+	c.SetPageSize(o.pageSize)
+	return c, nil
+}
+
+// MaxDepth returns how deep recursive listings descend into subfolders.
+func (c *Client) MaxDepth() int {
+	if c.maxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return c.maxDepth
+}
+
+// rateLimiter spaces calls at least interval apart. A nil limiter never waits.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter returns a limiter for perSecond calls per second, or nil if perSecond <= 0
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the caller may make its next call or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	if d := slot.Sub(now); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package drive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// escapeSeparators replaces path separators in a single Drive name, which may
+// legally contain them, so the name can't introduce extra directory levels.
+// The result is in Unicode NFC, the form Drive usually reports names in.
+func escapeSeparators(name string) string {
+	return norm.NFC.String(strings.NewReplacer("/", "_", "\\", "_").Replace(name))
+}
+
+// LocalPath returns where file is saved under destDir. Names are normalized to
+// NFC and separators inside them are escaped, and an error is returned for any "." or ".." component or any
+// result that would resolve outside destDir.
+func LocalPath(destDir string, file DriveFile) (string, error) {
+	parts := []string{destDir}
+	for _, dir := range strings.Split(file.Path, "/") {
+		if dir == "" {
+			continue
+		}
+		if dir == "." || dir == ".." {
+			return "", fmt.Errorf("refusing to save %q: folder path contains %q", file.DisplayName(), dir)
+		}
+		parts = append(parts, escapeSeparators(dir))
+	}
+
+	name := escapeSeparators(file.Name)
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("refusing to save file %s: invalid name %q", file.ID, file.Name)
+	}
+	parts = append(parts, name)
+
+	path := filepath.Join(parts...)
+	rel, err := filepath.Rel(filepath.Clean(destDir), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("refusing to save %q outside %s", file.DisplayName(), destDir)
+	}
+	return path, nil
+}
+
+// DestPath returns where DownloadFile saves file under destDir, and where
+// its local copy is looked for: the path of the file as SavedFile names it,
+// or of an existing copy under another Unicode normalization. Anything that
+// checks for local copies should use it, so it agrees with what DownloadFile
+// skips. A nil Client uses Drive's names and DefaultExportMap.
+func (c *Client) DestPath(destDir string, file DriveFile) (string, error) {
+	path, err := LocalPath(destDir, c.SavedFile(file))
+	if err != nil {
+		return "", err
+	}
+	return existingPath(path), nil
+}
+
+// existingPath returns path, or if nothing exists there, an existing path that
+// differs only in the Unicode normalization of its names. macOS, for one,
+// may store "café" decomposed (NFD) while Drive reports it composed (NFC).
+func existingPath(path string) string {
+	if _, err := os.Lstat(path); err == nil {
+		return path
+	}
+	base := filepath.Base(path)
+	dir := filepath.Dir(path)
+	if dir == path {
+		return path
+	}
+	parent := existingPath(dir)
+	if parent == dir && norm.NFC.IsNormalString(base) && norm.NFD.IsNormalString(base) {
+		// Plain names have no other normalized form to look for
+		return path
+	}
+
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return path
+	}
+	want := norm.NFC.String(base)
+	for _, e := range entries {
+		if norm.NFC.String(e.Name()) == want {
+			return filepath.Join(parent, e.Name())
+		}
+	}
+	return filepath.Join(parent, base)
+}
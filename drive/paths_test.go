@@ -0,0 +1,83 @@
+package drive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	dest := filepath.Join("out", "dir")
+
+	tests := []struct {
+		name    string
+		file    DriveFile
+		want    string
+		wantErr bool
+	}{
+		{name: "top level", file: DriveFile{Name: "a.txt"}, want: filepath.Join(dest, "a.txt")},
+		{name: "nested", file: DriveFile{Path: "x/y", Name: "a.txt"}, want: filepath.Join(dest, "x", "y", "a.txt")},
+		{name: "separator in name", file: DriveFile{Name: "a/b.txt"}, want: filepath.Join(dest, "a_b.txt")},
+		{name: "backslash in name", file: DriveFile{Name: `..\..\evil`}, want: filepath.Join(dest, ".._.._evil")},
+		{name: "dotdot name", file: DriveFile{Name: ".."}, wantErr: true},
+		{name: "empty name", file: DriveFile{Name: ""}, wantErr: true},
+		{name: "dotdot in path", file: DriveFile{Path: "x/../../etc", Name: "passwd"}, wantErr: true},
+		{name: "absolute path", file: DriveFile{Path: "/etc", Name: "passwd"}, want: filepath.Join(dest, "etc", "passwd")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LocalPath(dest, tt.file)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LocalPath() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LocalPath() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("LocalPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalPathUnicodeNormalization(t *testing.T) {
+	const (
+		nfcDir, nfdDir   = "r\u00e9sum\u00e9s", "re\u0301sume\u0301s"
+		nfcName, nfdName = "caf\u00e9.jpg", "cafe\u0301.jpg"
+	)
+	dest := t.TempDir()
+
+	got, err := LocalPath(dest, DriveFile{Path: nfdDir, Name: nfdName})
+	if err != nil {
+		t.Fatalf("LocalPath() returned error: %v", err)
+	}
+	if want := filepath.Join(dest, nfcDir, nfcName); got != want {
+		t.Errorf("LocalPath() = %q, want NFC %q", got, want)
+	}
+
+	// A copy saved decomposed, as macOS may do, is still found
+	dir := filepath.Join(dest, nfdDir)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, nfdName), []byte("12345"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := DriveFile{Path: nfcDir, Name: nfcName, Size: 5}
+	path, _ := LocalPath(dest, file)
+	if got := existingPath(path); got != filepath.Join(dir, nfdName) {
+		t.Errorf("existingPath() = %q, want the NFD copy", got)
+	}
+	if !IsLocalCopyCurrent(path, file, ExistSkip) {
+		t.Errorf("IsLocalCopyCurrent() = false for a copy saved in NFD")
+	}
+
+	// New files go into the existing NFD folder rather than a second one
+	if got := existingPath(filepath.Join(dest, nfcDir, "other.jpg")); got != filepath.Join(dir, "other.jpg") {
+		t.Errorf("existingPath() = %q, want a path in the existing NFD folder", got)
+	}
+}
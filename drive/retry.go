@@ -0,0 +1,171 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// DefaultMaxRetries is how many times a rate-limited or failed API call is retried
+	DefaultMaxRetries = 5
+	// DefaultRetryBaseDelay is the first backoff delay; it doubles on each attempt
+	DefaultRetryBaseDelay = time.Second
+	// retryMaxDelay caps both the computed backoff and any Retry-After value
+	retryMaxDelay = 2 * time.Minute
+)
+
+// rateLimitReasons are 403 reasons that mean "slow down" rather than "forbidden".
+var rateLimitReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+}
+
+// withRetry calls fn (a single API call) until it succeeds,
+// fails with an error that isn't worth retrying, or runs out of c's retries.
+// Every attempt waits for c's rate limiter. Between attempts it waits for the
+// duration in the server's Retry-After header if there is one, or an
+// exponential backoff with jitter otherwise.
+func withRetry[T any](ctx context.Context, c *Client, op string, fn func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+		c.apiCalls.Add(1)
+		v, err := fn()
+		if err != nil && c.onQuotaError != nil && IsQuotaError(err) {
+			c.onQuotaError()
+		}
+		if err == nil || attempt >= c.retries || !isRetryable(err) {
+			return v, err
+		}
+
+		delay, fromHeader := retryAfter(err)
+		if !fromHeader {
+			delay = backoff(c.retryBase(), attempt)
+		}
+		slog.Debug("retrying API call", "op", op, "attempt", attempt+1, "delay", delay, "retryAfter", fromHeader, "err", err)
+		if file, ok := eventFileFrom(ctx); ok {
+			c.emit(Event{Kind: EventRetry, File: file, TotalBytes: file.Size, Attempt: attempt + 1, Delay: delay, Err: err})
+			if progressChan := retryProgressFrom(ctx); progressChan != nil {
+				progressChan <- DownloadProgress{
+					FileID:     file.ID,
+					FileName:   file.DisplayName(),
+					TotalBytes: file.Size,
+					Retry:      attempt + 1,
+					MaxRetries: c.retries,
+					RetryDelay: delay,
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return v, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// APICalls returns how many Drive API calls the client has made, counting
+// every attempt of a retried call. Each one counts against the quota.
+func (c *Client) APICalls() int64 {
+	return c.apiCalls.Load()
+}
+
+// isRetryable reports whether err is a rate-limit or transient server error
+func isRetryable(err error) bool {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return false
+	}
+	switch {
+	case gerr.Code == http.StatusTooManyRequests:
+		return true
+	case gerr.Code >= 500:
+		return true
+	case gerr.Code == http.StatusForbidden:
+		return rateLimitReasons[errorReason(gerr)]
+	}
+	return false
+}
+
+// IsQuotaError reports whether err is Drive asking the caller to slow down:
+// a 429, or a 403 with a rate-limit reason
+func IsQuotaError(err error) bool {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return false
+	}
+	return gerr.Code == http.StatusTooManyRequests ||
+		gerr.Code == http.StatusForbidden && rateLimitReasons[errorReason(gerr)]
+}
+
+// SetQuotaErrorHook registers fn to be called whenever an API call is rate
+// limited, including attempts that are then retried. Callers can use it to
+// back off more broadly than a single call's retries. Set it before starting
+// any calls; nil removes the hook.
+func (c *Client) SetQuotaErrorHook(fn func()) {
+	c.onQuotaError = fn
+}
+
+// retryAfter returns the wait requested by a Retry-After header on err, which
+// may be given in seconds or as an HTTP date
+func retryAfter(err error) (time.Duration, bool) {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) || gerr.Header == nil {
+		return 0, false
+	}
+	value := gerr.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = time.Until(t)
+	} else {
+		return 0, false
+	}
+	return min(max(d, 0), retryMaxDelay), true
+}
+
+// SetRetries sets how many times a rate-limited or failed API call is
+// retried; 0 disables retries.
+func (c *Client) SetRetries(n int) {
+	c.retries = max(n, 0)
+}
+
+// SetRetryBaseDelay sets the backoff before the first retry, which doubles
+// on each retry after it. Each delay is jittered down by up to half so
+// concurrent callers don't retry in lockstep. 0 restores
+// DefaultRetryBaseDelay.
+func (c *Client) SetRetryBaseDelay(d time.Duration) {
+	c.retryBaseDelay = max(d, 0)
+}
+
+// retryBase returns the configured base delay, or DefaultRetryBaseDelay if unset
+func (c *Client) retryBase() time.Duration {
+	if c.retryBaseDelay <= 0 {
+		return DefaultRetryBaseDelay
+	}
+	return c.retryBaseDelay
+}
+
+// backoff returns the exponential delay from base before retry number attempt+1
+func backoff(base time.Duration, attempt int) time.Duration {
+	d := retryMaxDelay
+	if attempt < 32 && base < retryMaxDelay>>attempt {
+		d = base << attempt
+	}
+	return d/2 + rand.N(d/2+1)
+}
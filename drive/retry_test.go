@@ -0,0 +1,72 @@
+package drive
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestBackoff(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt, want := range []time.Duration{base, 2 * base, 4 * base, 8 * base} {
+		for range 20 {
+			if d := backoff(base, attempt); d < want/2 || d > want {
+				t.Fatalf("backoff(%v, %d) = %v, want between %v and %v", base, attempt, d, want/2, want)
+			}
+		}
+	}
+	if d := backoff(base, 40); d < retryMaxDelay/2 || d > retryMaxDelay {
+		t.Errorf("backoff(%v, 40) = %v, want capped at %v", base, d, retryMaxDelay)
+	}
+}
+
+func TestCategorizeError(t *testing.T) {
+	// Downloads leave Drive's JSON in Body rather than parsing it
+	quota := &googleapi.Error{Code: http.StatusForbidden, Body: `{"error":{"code":403,"message":"The download quota for this file has been exceeded.","errors":[{"reason":"downloadQuotaExceeded"}]}}`}
+	for err, want := range map[error]ErrorCategory{
+		quota: ErrorQuota,
+		&googleapi.Error{Code: http.StatusTooManyRequests}:                                                 ErrorQuota,
+		&googleapi.Error{Code: http.StatusNotFound}:                                                        ErrorNotFound,
+		&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "forbidden"}}}: ErrorPermission,
+		io.ErrUnexpectedEOF:     ErrorNetwork,
+		errors.New("disk full"): ErrorOther,
+	} {
+		if got := CategorizeError(apiError("download file", err)); got != want {
+			t.Errorf("CategorizeError(%v) = %q, want %q", err, got, want)
+		}
+	}
+
+	msg := apiError("download file", quota).Error()
+	if !strings.Contains(msg, "HTTP 403 downloadQuotaExceeded") || !strings.Contains(msg, "quota for this file") || strings.Contains(msg, "{") {
+		t.Errorf("apiError message = %q, want the status, reason, and Drive's message", msg)
+	}
+}
+
+func TestAPIKeyQuota(t *testing.T) {
+	daily := &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "dailyLimitExceeded"}}}
+	download := &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "downloadQuotaExceeded"}}}
+
+	key := &Client{apiKey: true}
+	if !key.IsAPIKeyQuota(apiError("download file", daily)) {
+		t.Error("a daily limit with an API key isn't reported")
+	}
+	if key.IsAPIKeyQuota(download) {
+		t.Error("a file's download quota is reported as the API key's")
+	}
+	if (&Client{}).IsAPIKeyQuota(daily) {
+		t.Error("a daily limit without an API key is reported")
+	}
+
+	err := key.WithQuotaAdvice(listError("folder", daily))
+	if !errors.Is(err, daily) || !strings.Contains(err.Error(), "-oauth") {
+		t.Errorf("WithQuotaAdvice = %v, want the error with advice to use -oauth", err)
+	}
+	if err := key.WithQuotaAdvice(download); err != download {
+		t.Errorf("WithQuotaAdvice changed an unrelated error to %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// revisionFields are the fields requested for each revision
+const revisionFields = "nextPageToken, revisions(id, modifiedTime, size, md5Checksum, lastModifyingUser(displayName))"
+
+// ErrNoRevisions is returned by ListRevisions when a file's earlier versions
+// can't be downloaded: Google Workspace files, whose revisions have no
+// content of their own, and files the caller may only read the current
+// version of.
+var ErrNoRevisions = errors.New("revisions not available for this file")
+
+// Revision is one stored version of a file's content
+type Revision struct {
+	ID           string
+	ModifiedTime time.Time
+	Size         int64
+	MD5Checksum  string
+	// ModifiedBy is the display name of whoever saved it, if Drive says
+	ModifiedBy string
+}
+
+// ListRevisions returns the stored versions of file, oldest first; the last
+// one is the current content. It returns ErrNoRevisions if they can't be
+// downloaded, so callers can fall back to the current version.
+func (c *Client) ListRevisions(ctx context.Context, file DriveFile) ([]Revision, error) {
+	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
+		return nil, ErrNoRevisions
+	}
+
+	var revisions []Revision
+	pageToken := ""
+	for {
+		slog.Debug("Revisions.List", "id", file.ID, "nextPage", pageToken != "")
+		list, err := withRetry(ctx, c, "Revisions.List", func() (*drive.RevisionList, error) {
+			return c.service.Revisions(ctx, file.ID, revisionFields, pageToken)
+		})
+		if err != nil {
+			var gerr *googleapi.Error
+			if errors.As(err, &gerr) && (gerr.Code == http.StatusForbidden || gerr.Code == http.StatusNotFound || permissionReasons[errorReason(gerr)]) {
+				return nil, fmt.Errorf("%w: %w", ErrNoRevisions, err)
+			}
+			return nil, fmt.Errorf("unable to list revisions: %w", err)
+		}
+		for _, r := range list.Revisions {
+			rev := Revision{ID: r.Id, Size: r.Size, MD5Checksum: r.Md5Checksum}
+			if t, err := time.Parse(time.RFC3339, r.ModifiedTime); err == nil {
+				rev.ModifiedTime = t
+			}
+			if r.LastModifyingUser != nil {
+				rev.ModifiedBy = r.LastModifyingUser.DisplayName
+			}
+			revisions = append(revisions, rev)
+		}
+		if pageToken = list.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	if len(revisions) == 0 {
+		return nil, ErrNoRevisions
+	}
+	return revisions, nil
+}
+
+// PickRevision finds a revision by ID or by its 1-based position in
+// revisions, oldest first; negative positions count back from the current
+// one, so -1 is the current content and -2 the version before it.
+func PickRevision(revisions []Revision, spec string) (Revision, error) {
+	spec = strings.TrimSpace(spec)
+	for _, r := range revisions {
+		if r.ID == spec {
+			return r, nil
+		}
+	}
+	n, err := strconv.Atoi(spec)
+	if err != nil {
+		return Revision{}, fmt.Errorf("no revision %q", spec)
+	}
+	i := n - 1
+	if n < 0 {
+		i = len(revisions) + n
+	}
+	if n == 0 || i < 0 || i >= len(revisions) {
+		return Revision{}, fmt.Errorf("revision %d out of range (the file has %d)", n, len(revisions))
+	}
+	return revisions[i], nil
+}
+
+// AtRevision returns file as it was at r: downloading it fetches that
+// version's content instead of the current one. It is named after the
+// revision, e.g. "notes (rev 2024-01-02 030405).txt", so it is saved beside
+// the current version rather than over it.
+func (file DriveFile) AtRevision(r Revision) DriveFile {
+	file.RevisionID = r.ID
+	file.Size = r.Size
+	file.MD5Checksum = r.MD5Checksum
+	rev := r.ID
+	if !r.ModifiedTime.IsZero() {
+		file.ModifiedTime = r.ModifiedTime
+		rev = r.ModifiedTime.UTC().Format("2006-01-02 150405")
+	}
+	ext := path.Ext(file.Name)
+	file.Name = fmt.Sprintf("%s (rev %s)%s", strings.TrimSuffix(file.Name, ext), rev, ext)
+	return file
+}
@@ -0,0 +1,70 @@
+package drive
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// filesAPI is the part of the Drive API a Client calls. apiFiles implements
+// it with the real service; tests substitute a fake.
+type filesAPI interface {
+	// Get fetches a file's metadata, limited to fields
+	Get(ctx context.Context, fileID, fields string) (*drive.File, error)
+	// List fetches one page of the files matching query
+	List(ctx context.Context, query, fields, pageToken string, pageSize int64) (*drive.FileList, error)
+	// Download fetches a file's content, sending header with the request
+	// (e.g. a Range)
+	Download(ctx context.Context, fileID string, header http.Header) (*http.Response, error)
+	// Export fetches a Google Workspace file converted to mimeType
+	Export(ctx context.Context, fileID, mimeType string) (*http.Response, error)
+	// Revisions fetches one page of a file's revisions, limited to fields
+	Revisions(ctx context.Context, fileID, fields, pageToken string) (*drive.RevisionList, error)
+	// DownloadRevision fetches the content of one revision of a file
+	DownloadRevision(ctx context.Context, fileID, revisionID string) (*http.Response, error)
+}
+
+// apiFiles is the filesAPI of a *drive.Service
+type apiFiles struct {
+	service *drive.Service
+}
+
+func (a apiFiles) Get(ctx context.Context, fileID, fields string) (*drive.File, error) {
+	return a.service.Files.Get(fileID).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+func (a apiFiles) List(ctx context.Context, query, fields, pageToken string, pageSize int64) (*drive.FileList, error) {
+	call := a.service.Files.List().Q(query).Fields(googleapi.Field(fields)).PageSize(pageSize)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (a apiFiles) Download(ctx context.Context, fileID string, header http.Header) (*http.Response, error) {
+	call := a.service.Files.Get(fileID).Context(ctx)
+	for key, values := range header {
+		for _, v := range values {
+			call.Header().Add(key, v)
+		}
+	}
+	return call.Download()
+}
+
+func (a apiFiles) Export(ctx context.Context, fileID, mimeType string) (*http.Response, error) {
+	return a.service.Files.Export(fileID, mimeType).Context(ctx).Download()
+}
+
+func (a apiFiles) Revisions(ctx context.Context, fileID, fields, pageToken string) (*drive.RevisionList, error) {
+	call := a.service.Revisions.List(fileID).Fields(googleapi.Field(fields))
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (a apiFiles) DownloadRevision(ctx context.Context, fileID, revisionID string) (*http.Response, error) {
+	return a.service.Revisions.Get(fileID, revisionID).Context(ctx).Download()
+}
@@ -0,0 +1,153 @@
+package drive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// OutputStructure decides which folders under the output directory files
+// are saved in.
+type OutputStructure string
+
+const (
+	// StructureMirror recreates each file's Drive folder path (the default)
+	StructureMirror OutputStructure = "mirror"
+	// StructureFlat saves every file directly in the output directory
+	StructureFlat OutputStructure = "flat"
+	// StructureDate sorts files into year/month folders by modified time
+	StructureDate OutputStructure = "date"
+	// StructureType sorts files into folders by kind, e.g. images or videos
+	StructureType OutputStructure = "type"
+)
+
+// unknownDateFolder holds files Drive reports no modified time for under StructureDate
+const unknownDateFolder = "undated"
+
+// ParseOutputStructure parses mirror, flat, date, or type ("" is mirror).
+func ParseOutputStructure(s string) (OutputStructure, error) {
+	switch st := OutputStructure(strings.ToLower(strings.TrimSpace(s))); st {
+	case "":
+		return StructureMirror, nil
+	case StructureMirror, StructureFlat, StructureDate, StructureType:
+		return st, nil
+	}
+	return "", fmt.Errorf("unknown output structure %q (want mirror, flat, date, or type)", s)
+}
+
+// SetOutputStructure sets how downloads are arranged under the output
+// directory. "" keeps StructureMirror.
+func (c *Client) SetOutputStructure(s OutputStructure) {
+	c.structure = s
+}
+
+// DefaultFlattenDelimiter joins folder and file names under SetFlattenNames.
+const DefaultFlattenDelimiter = "__"
+
+// ParseFlattenDelimiter checks a delimiter for SetFlattenNames, which must be
+// non-empty and can't contain path separators.
+func ParseFlattenDelimiter(s string) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("the delimiter can't be empty")
+	}
+	if strings.ContainsAny(s, `/\`) {
+		return "", fmt.Errorf("the delimiter can't contain path separators, but got %q", s)
+	}
+	return s, nil
+}
+
+// SetFlattenNames saves every file directly in the output directory, its
+// folder path (as arranged by the output structure) kept in its name with
+// delim in place of the separators: sub/folder/file.jpg becomes
+// sub__folder__file.jpg. "" saves files in folders again.
+func (c *Client) SetFlattenNames(delim string) {
+	c.flattenDelim = delim
+}
+
+// maxNameBytes is the longest file name most filesystems accept
+const maxNameBytes = 255
+
+// flattenedName returns file's name prefixed with the folders of dir,
+// joined by the client's flatten delimiter. A prefix that would make the
+// name too long is cut short and tagged with a hash of all of it, so files
+// from different deep folders still get different names.
+func (c *Client) flattenedName(dir, name string) string {
+	var parts []string
+	for part := range strings.SplitSeq(dir, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	flat := strings.Join(append(parts, name), c.flattenDelim)
+	if len(flat) <= maxNameBytes || len(parts) == 0 {
+		return flat
+	}
+
+	prefix := strings.Join(parts, c.flattenDelim)
+	sum := sha256.Sum256([]byte(prefix))
+	tag := "~" + hex.EncodeToString(sum[:4])
+	room := max(maxNameBytes-len(name)-len(c.flattenDelim)-len(tag), 0)
+	for room > 0 && !utf8.RuneStart(prefix[room]) {
+		room--
+	}
+	return prefix[:room] + tag + c.flattenDelim + name
+}
+
+// structuredPath returns the folder path file is saved under, relative to
+// the output directory
+func (c *Client) structuredPath(file DriveFile) string {
+	switch c.structure {
+	case StructureFlat:
+		return ""
+	case StructureDate:
+		if file.ModifiedTime.IsZero() {
+			return unknownDateFolder
+		}
+		return file.ModifiedTime.Format("2006/01")
+	case StructureType:
+		return typeFolder(file.MimeType)
+	}
+	return file.Path
+}
+
+// archiveMimeTypes are the MIME types StructureType files under "archives"
+var archiveMimeTypes = map[string]bool{
+	"application/zip":              true,
+	"application/x-zip-compressed": true,
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-tar":            true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/vnd.rar":          true,
+	"application/x-bzip2":          true,
+	"application/x-xz":             true,
+}
+
+// typeFolder returns the StructureType folder for a MIME type
+func typeFolder(mimeType string) string {
+	kind, _, _ := strings.Cut(mimeType, "/")
+	switch {
+	case kind == "image":
+		return "images"
+	case kind == "video":
+		return "videos"
+	case kind == "audio":
+		return "audio"
+	case archiveMimeTypes[mimeType]:
+		return "archives"
+	case kind == "text",
+		mimeType == "application/pdf",
+		mimeType == "application/msword",
+		mimeType == "application/rtf",
+		mimeType == "application/epub+zip",
+		strings.HasPrefix(mimeType, "application/vnd.openxmlformats-officedocument."),
+		strings.HasPrefix(mimeType, "application/vnd.oasis.opendocument."),
+		strings.HasPrefix(mimeType, "application/vnd.ms-"),
+		strings.HasPrefix(mimeType, "application/vnd.google-apps."):
+		return "documents"
+	}
+	return "other"
+}
@@ -0,0 +1,44 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// NewHTTPClient returns an HTTP client for Drive API traffic. Requests go
+// through proxyURL when it is set, otherwise through the proxy named by
+// HTTP_PROXY/HTTPS_PROXY (honoring NO_PROXY).
+func NewHTTPClient(proxyURL string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", proxyURL)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// contextWithHTTPClient returns a context that makes the oauth2 package send
+// its requests, including the token exchange and refreshes, through hc.
+func contextWithHTTPClient(ctx context.Context, hc *http.Client) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, hc)
+}
+
+// httpClientFrom returns the client stored by contextWithHTTPClient, or a
+// default client that honors the proxy environment variables.
+func httpClientFrom(ctx context.Context) *http.Client {
+	if hc, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && hc != nil {
+		return hc
+	}
+	hc, _ := NewHTTPClient("")
+	return hc
+}
@@ -0,0 +1,90 @@
+package drive
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+func TestListingRequestsCompression(t *testing.T) {
+	const body = `{"files":[{"id":"f1","name":"a.txt","mimeType":"text/plain","size":"3"}]}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("%s %s sent Accept-Encoding %q, want gzip", r.Method, r.URL.Path, r.Header.Get("Accept-Encoding"))
+			w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(body))
+		gz.Close()
+	}))
+	defer srv.Close()
+
+	base, err := NewHTTPClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	clients := map[string]*http.Client{
+		"api key": apiKeyHTTPClient(base, "key"),
+		"oauth":   oauth2.NewClient(contextWithHTTPClient(ctx, base), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})),
+	}
+
+	for name, hc := range clients {
+		t.Run(name, func(t *testing.T) {
+			service, err := drive.NewService(ctx, option.WithHTTPClient(hc), option.WithEndpoint(srv.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := &Client{service: apiFiles{service}}
+
+			files, err := c.ListFiles(ctx, "folder")
+			if err != nil {
+				t.Fatalf("ListFiles returned error: %v", err)
+			}
+			if len(files) != 1 || files[0].ID != "f1" || files[0].Size != 3 {
+				t.Errorf("ListFiles = %v, want the one file from the gzipped response", files)
+			}
+		})
+	}
+}
+
+func TestLimitConnections(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	hc := limitConnections(srv.Client(), 1)
+	first, err := hc.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The only slot is taken until the first body is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := hc.Do(req); err == nil {
+		t.Fatal("second request got a connection while the first was open")
+	}
+
+	first.Body.Close()
+	first.Body.Close()
+	second, err := hc.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request after closing the first body: %v", err)
+	}
+	second.Body.Close()
+}
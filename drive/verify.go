@@ -0,0 +1,64 @@
+package drive
+
+import (
+	"fmt"
+	"os"
+)
+
+// VerifyStatus is the outcome of checking a local copy against Drive's metadata.
+type VerifyStatus string
+
+const (
+	// VerifyOK means the local copy matches every check Drive's metadata allows
+	VerifyOK VerifyStatus = "ok"
+	// VerifyMissing means there is no local file (or it isn't a regular file)
+	VerifyMissing VerifyStatus = "missing"
+	// VerifySizeMismatch means the local file's size differs from Drive's
+	VerifySizeMismatch VerifyStatus = "size mismatch"
+	// VerifyChecksumMismatch means the local file's MD5 differs from Drive's
+	VerifyChecksumMismatch VerifyStatus = "checksum mismatch"
+	// VerifyInvalidName means the file's name can't be mapped to a safe local path
+	VerifyInvalidName VerifyStatus = "invalid name"
+)
+
+// VerifyResult describes one file checked by VerifyLocalCopy.
+type VerifyResult struct {
+	File      DriveFile
+	LocalPath string
+	Status    VerifyStatus
+	// Detail explains a mismatch, e.g. the two sizes
+	Detail string
+}
+
+// VerifyLocalCopy checks that file was downloaded intact into destDir: the
+// local copy must exist, have the size Drive reports, and, when Drive has an
+// MD5 for it, the same checksum. Files Drive reports neither for are only
+// checked for presence.
+func VerifyLocalCopy(destDir string, file DriveFile) VerifyResult {
+	localPath, err := LocalPath(destDir, file)
+	if err != nil {
+		return VerifyResult{File: file, Status: VerifyInvalidName, Detail: err.Error()}
+	}
+	localPath = existingPath(localPath)
+	r := VerifyResult{File: file, LocalPath: localPath, Status: VerifyOK}
+
+	info, err := os.Stat(localPath)
+	if err != nil || !info.Mode().IsRegular() {
+		r.Status = VerifyMissing
+		return r
+	}
+
+	if file.Size > 0 && info.Size() != file.Size {
+		r.Status = VerifySizeMismatch
+		r.Detail = fmt.Sprintf("local %d bytes, Drive %d bytes", info.Size(), file.Size)
+		return r
+	}
+
+	if file.MD5Checksum != "" {
+		if sum := fileMD5(localPath); sum != file.MD5Checksum {
+			r.Status = VerifyChecksumMismatch
+			r.Detail = fmt.Sprintf("local %s, Drive %s", sum, file.MD5Checksum)
+		}
+	}
+	return r
+}
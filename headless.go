@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"google-drive-dl/drive"
+	"google-drive-dl/tui"
+)
+
+// readLinksFile reads Drive folder links from a file, one per line, or from
+// stdin when path is "-". Blank lines and # comments are skipped; other lines
+// that aren't folder links are skipped with a warning.
+func readLinksFile(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = readStdin()
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read links file: %w", err)
+	}
+
+	links, invalid := drive.ParseLinks(string(data))
+	for _, l := range invalid {
+		slog.Warn("skipping line that isn't a Google Drive folder link (comment it out with #)", "file", path, "line", l.Line, "text", l.Text)
+	}
+	if len(links) == 0 {
+		return nil, fmt.Errorf("no valid Google Drive folder links in %s", path)
+	}
+	return links, nil
+}
+
+// parseLinkArgs validates folder links given as positional arguments. File
+// links are refused with a pointer to -file.
+func parseLinkArgs(args []string) ([]string, error) {
+	var links []string
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if _, err := drive.ExtractFolderID(arg); err != nil {
+			if _, err := drive.ExtractFileID(arg); err == nil {
+				// Arguments are folders; single files have their own mode
+				return nil, fmt.Errorf("%q is not a folder link; to download a single file, use -file %s", arg, arg)
+			}
+			return nil, fmt.Errorf("%q is not a Google Drive folder link", arg)
+		}
+		links = append(links, arg)
+	}
+	return links, nil
+}
+
+// collectLinks returns the links from linksFile (if any) followed by argLinks,
+// for the headless modes that need at least one
+func collectLinks(linksFile string, argLinks []string) ([]string, error) {
+	var links []string
+	if linksFile != "" {
+		fileLinks, err := readLinksFile(linksFile)
+		if err != nil {
+			return nil, err
+		}
+		links = fileLinks
+	}
+	links = append(links, argLinks...)
+	if len(links) == 0 {
+		return nil, fmt.Errorf("no folder links given; pass them with -f or as arguments")
+	}
+	return links, nil
+}
+
+// readStdin reads all of stdin, refusing to block on an interactive terminal
+func readStdin() ([]byte, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return nil, fmt.Errorf("-f - expects links piped on stdin, but stdin is a terminal")
+	}
+	return io.ReadAll(os.Stdin)
+}
+
+// splitTerms splits a comma-separated search string into trimmed, non-empty terms
+func splitTerms(s string) []string {
+	var terms []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// exitNoFiles is the exit status when the folders turn out to be empty, so
+// scripts can tell "nothing there" apart from a failure
+const exitNoFiles = 2
+
+// errNoFiles is returned when the listed folders contain no files at all
+var errNoFiles = errors.New("no downloadable files found in the provided folders")
+
+// errNothingToRetry is returned when a report given to -retry-report has no failures
+var errNothingToRetry = errors.New("the report has no failed files to retry")
+
+// retryFiles reads a report from an earlier run and fetches the current
+// metadata of the files that failed in it, so they can be downloaded again
+// without listing their folders. Files that can no longer be fetched are
+// left out with a warning.
+func retryFiles(ctx context.Context, client *drive.Client, reportPath string) ([]drive.DriveFile, error) {
+	report, err := tui.ReadReport(reportPath)
+	if err != nil {
+		return nil, err
+	}
+	failed := report.FailedFiles()
+	if len(failed) == 0 {
+		return nil, errNothingToRetry
+	}
+
+	var files []drive.DriveFile
+	for _, rf := range failed {
+		file, err := client.GetFile(ctx, rf.ID)
+		if err != nil {
+			slog.Warn("not retrying a file that can't be fetched", "file", rf.Name, "err", err)
+			continue
+		}
+		// Keep the listing's fields so the retry is named and laid out the
+		// way the first attempt would have been
+		files = append(files, rf.Restore(file))
+	}
+	if len(files) == 0 {
+		return nil, errNoFiles
+	}
+	return files, nil
+}
+
+// runExportLinks lists the folders, applies the filters, and prints a web
+// link for every matching file to stdout
+func runExportLinks(ctx context.Context, client *drive.Client, links []string, filter fileFilter, maxConcurrent int) error {
+	files, err := client.ListFilesFromFoldersWithDepth(ctx, links, client.MaxDepth(), maxConcurrent)
+	if err != nil {
+		if len(files) == 0 {
+			return err
+		}
+		// Partial results are still worth printing
+		slog.Warn("some folders could not be listed, printing the links found", "err", err)
+	}
+	if len(files) == 0 {
+		return errNoFiles
+	}
+
+	for _, f := range filter.apply(files) {
+		fmt.Println(f.WebLink())
+	}
+	return nil
+}
+
+// runTree lists the folders and prints the matching files as an indented
+// tree of folders, each with the number and total size of its files
+func runTree(ctx context.Context, client *drive.Client, links []string, filter fileFilter, maxConcurrent int) error {
+	files, err := client.ListFilesFromFoldersWithDepth(ctx, links, client.MaxDepth(), maxConcurrent)
+	if err != nil {
+		if len(files) == 0 {
+			return err
+		}
+		// The tree is still useful without the folders that failed
+		slog.Warn("some folders could not be listed, the tree leaves them out", "err", err)
+	}
+	if len(files) == 0 {
+		return errNoFiles
+	}
+
+	files = filter.apply(files)
+	root := buildTree(files)
+	printTree(os.Stdout, root, 0)
+	fmt.Printf("%d files, %s\n", root.count, drive.FormatSize(root.bytes))
+	return nil
+}
+
+// fileFilter holds the filters the headless modes apply to a listing: the
+// search terms, -name-only, -glob, -since and the MIME type lists
+type fileFilter struct {
+	terms    []string
+	nameOnly bool
+	globs    []string
+	since    time.Time
+	mimes    drive.MimeFilter
+}
+
+// apply returns the files that pass every filter
+func (ff fileFilter) apply(files []drive.DriveFile) []drive.DriveFile {
+	if ff.nameOnly {
+		files = drive.FilterFilesByName(files, ff.terms)
+	} else {
+		files = drive.FilterFiles(files, ff.terms)
+	}
+	files = ff.mimes.Filter(drive.FilterFilesByGlob(files, ff.globs))
+	return drive.FilterModifiedSince(files, ff.since)
+}
+
+// readMimeTypes reads a -mime-allow-file or -mime-deny-file; no path means no list
+func readMimeTypes(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	types, err := drive.ParseMimeTypes(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return types, nil
+}
+
+// errVerifyFailed is returned by runVerify when any local copy is missing or differs
+var errVerifyFailed = errors.New("some local copies are missing or differ from Drive")
+
+// runVerify lists the folders and checks every matching file's local copy in
+// destDir against Drive's metadata, printing one line per problem and a
+// summary. Nothing is downloaded.
+func runVerify(ctx context.Context, client *drive.Client, links []string, filter fileFilter, destDir string, maxConcurrent int) error {
+	files, err := client.ListFilesFromFoldersWithDepth(ctx, links, client.MaxDepth(), maxConcurrent)
+	if err != nil {
+		if len(files) == 0 {
+			return err
+		}
+		// Files in folders that failed to list simply go unchecked
+		slog.Warn("some folders could not be listed, their files go unchecked", "err", err)
+	}
+	if len(files) == 0 {
+		return errNoFiles
+	}
+
+	files = filter.apply(files)
+
+	counts := make(map[drive.VerifyStatus]int)
+	for _, f := range files {
+		r := drive.VerifyLocalCopy(destDir, client.SavedFile(f))
+		counts[r.Status]++
+		if r.Status == drive.VerifyOK {
+			continue
+		}
+		line := fmt.Sprintf("%-17s %s", strings.ToUpper(string(r.Status)), f.DisplayName())
+		if r.Detail != "" {
+			line += " (" + r.Detail + ")"
+		}
+		fmt.Println(line)
+	}
+
+	bad := len(files) - counts[drive.VerifyOK]
+	fmt.Printf("Verified %d files: %d ok, %d missing, %d size mismatches, %d checksum mismatches\n",
+		len(files), counts[drive.VerifyOK], counts[drive.VerifyMissing],
+		counts[drive.VerifySizeMismatch], counts[drive.VerifyChecksumMismatch])
+	if bad > 0 {
+		return errVerifyFailed
+	}
+	return nil
+}
+
+// runSingleFile downloads one file by ID or link, either into destDir or,
+// with toStdout, straight to stdout for piping into another process. With
+// manifest, a file saved into destDir is recorded in its manifest. A
+// revision (see drive.PickRevision) downloads that version instead, or the
+// current one if the file's revisions aren't available.
+func runSingleFile(ctx context.Context, client *drive.Client, fileArg, revision, destDir string, toStdout, manifest bool) error {
+	id, err := drive.ExtractFileID(fileArg)
+	if err != nil {
+		return err
+	}
+	file, err := client.GetFile(ctx, id)
+	if err != nil {
+		return err
+	}
+	if revision != "" {
+		if file, err = atRevision(ctx, client, file, revision); err != nil {
+			return err
+		}
+	}
+
+	if toStdout {
+		return client.DownloadFileTo(ctx, file, os.Stdout, nil)
+	}
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return fmt.Errorf("unable to create output directory: %w", err)
+	}
+	if !manifest {
+		return client.DownloadFile(ctx, file, destDir, nil)
+	}
+
+	// The final progress says where the file was actually saved
+	progress := make(chan drive.DownloadProgress, 16)
+	finalDone := make(chan drive.DownloadProgress)
+	go func() {
+		var final drive.DownloadProgress
+		for p := range progress {
+			if p.Done {
+				final = p
+			}
+		}
+		finalDone <- final
+	}()
+	err = client.DownloadFile(ctx, file, destDir, progress)
+	close(progress)
+	final := <-finalDone
+	if err != nil || final.LocalPath == "" || final.Skipped {
+		return err
+	}
+	return drive.UpdateManifest(destDir, []drive.ManifestEntry{drive.NewManifestEntry(destDir, file, final.LocalPath, time.Now())})
+}
+
+// atRevision returns file at the revision named by spec, or file itself with
+// a warning if its revisions aren't available
+func atRevision(ctx context.Context, client *drive.Client, file drive.DriveFile, spec string) (drive.DriveFile, error) {
+	revisions, err := client.ListRevisions(ctx, file)
+	if errors.Is(err, drive.ErrNoRevisions) {
+		slog.Warn("revisions not available, downloading the current version", "file", file.DisplayName(), "err", err)
+		return file, nil
+	}
+	if err != nil {
+		return file, err
+	}
+	r, err := drive.PickRevision(revisions, spec)
+	if err != nil {
+		return file, fmt.Errorf("%s: %w", file.DisplayName(), err)
+	}
+	slog.Info("downloading revision", "file", file.DisplayName(), "revision", r.ID, "modified", r.ModifiedTime)
+	return file.AtRevision(r), nil
+}
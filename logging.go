@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+)
+
+// logLevel is the minimum level logged, set from -quiet/-verbose
+var logLevel = new(slog.LevelVar)
+
+// setupLogging installs the default slog logger. -quiet limits output to
+// errors and -verbose adds debug messages for API calls and skip decisions.
+func setupLogging(quiet, verbose bool) {
+	switch {
+	case quiet:
+		logLevel.Set(slog.LevelError)
+	case verbose:
+		logLevel.Set(slog.LevelDebug)
+	default:
+		logLevel.Set(slog.LevelInfo)
+	}
+	slog.SetDefault(newLogger(os.Stderr))
+}
+
+// newLogger returns a text logger without timestamps, which are noise on a terminal
+func newLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: logLevel,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// detachLoggingFromTerminal stops logs from drawing over the TUI. If stderr is
+// redirected (e.g. 2>debug.log) logging continues there.
+func detachLoggingFromTerminal() {
+	if info, err := os.Stderr.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		return
+	}
+	slog.SetDefault(newLogger(io.Discard))
+}
+
+// fatal logs an error and exits with status 1
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
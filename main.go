@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"text/template"
+	"time"
 
+	"google-drive-dl/cache"
 	"google-drive-dl/drive"
 	"google-drive-dl/tui"
 
@@ -18,32 +23,478 @@ func main() {
 	godotenv.Load()
 
 	useOAuth := flag.Bool("oauth", false, "Force OAuth authentication (recommended, avoids quota issues)")
-	apiKey := flag.String("k", "", "Google Drive API key (or set GOOGLE_API_KEY env var)")
+	apiKey := flag.String("k", "", "Google Drive API key. Without it the key comes from GOOGLE_API_KEY (also read from .env), then from api_key=... in ~/.config/gdrive-dl/credentials")
 	credentialsFile := flag.String("credentials", "credentials.json", "Path to OAuth credentials.json file")
-	linksFile := flag.String("f", "", "Path to file containing Google Drive links (one per line)")
+	serviceAccount := flag.String("service-account", "", "Path to a service account key JSON file (authenticates as that account)")
+	proxy := flag.String("proxy", "", "HTTP proxy URL for Drive traffic (default: HTTP_PROXY/HTTPS_PROXY)")
+	scope := flag.String("scope", "readonly", "OAuth scopes (comma-separated): readonly, drive, file, metadata.readonly, or a scope URL")
+	myDrive := flag.Bool("root", false, "List your own My Drive as well as any links (needs OAuth or a service account); \"root\" also works as a link")
+	linksFile := flag.String("f", "", "Path to file containing Google Drive links (one per line), or - for stdin")
 	destDir := flag.String("o", "./output", "Output directory for downloaded files")
-	maxConcurrent := flag.Int("c", 4, "Maximum concurrent downloads")
+	maxConcurrent := flag.Int("c", 4, "Maximum concurrent downloads and folder listings")
 	downloadAll := flag.Bool("a", false, "Download all matching files without selection prompt")
+	force := flag.Bool("force", false, "Re-download files that already exist locally (toggle with x in the file list)")
+	showPaths := flag.Bool("show-paths", true, "Show each file's Drive path in the file list (toggle with p); -show-paths=false shows bare names")
+	browse := flag.Bool("browse", false, "Start the file list in folder view, one folder at a time (toggle with F)")
+	onlyMissing := flag.Bool("only-missing", false, "Hide files that already exist locally from the list (toggle with h)")
+	offline := flag.Bool("offline", false, "Browse cached listings only, without authenticating or downloading")
+	syncMode := flag.Bool("sync", false, "Download only files new or changed since the last listing (implies -a and -on-exist overwrite) and report files deleted upstream")
 	searchTerms := flag.String("s", "", "Search terms (comma-separated) to filter files")
+	sinceFlag := flag.String("since", "", "Only list files modified on or after a date (2024-01-01) or within a duration (168h, 7d); files without a modified time are left out")
+	dedupeMD5 := flag.Bool("dedupe-md5", false, "Also drop files with the same size and MD5 as a file under an earlier link (files with the same ID are always listed once)")
+	mimeAllowFile := flag.String("mime-allow-file", "", "Only list files whose MIME type is in this file (one per line, # comments, image/* for a family)")
+	mimeDenyFile := flag.String("mime-deny-file", "", "Leave out files whose MIME type is in this file, even if allowed (same format as -mime-allow-file)")
+	globFlag := flag.String("glob", "", "Only files whose names match one of these shell globs (comma-separated), e.g. '*.jpg,IMG_*'; case is ignored")
+	nameOnly := flag.Bool("name-only", false, "Match search terms against file names only, not folder paths")
+	exportLinks := flag.Bool("export-links", false, "Print a web link for every file matching -s in the folders from -f, then exit")
+	tree := flag.Bool("tree", false, "Print the folders from -f as an indented tree with per-folder file counts and sizes, then exit")
+	singleFile := flag.String("file", "", "Download a single file by ID or link into -o (or to stdout with -stdout), then exit")
+	revision := flag.String("revision", "", "With -file, download this revision instead of the current version: a revision ID, or its number oldest first (1 = oldest, -1 = current, -2 = the one before)")
+	toStdout := flag.Bool("stdout", false, "With -file, write the file's content to stdout")
+	verify := flag.Bool("verify", false, "Check local copies in -o of the files in -f against Drive (presence, size, MD5) without downloading, then exit")
+	timeout := flag.Duration("timeout", 0, "Per-file download timeout, e.g. 10m (0 = no timeout)")
+	cacheInfo := flag.Bool("cache-info", false, "Print cache statistics and exit")
+	cacheClear := flag.Bool("cache-clear", false, "Remove all cached folder listings and exit")
+	cachePrune := flag.Bool("cache-prune", false, "Remove cached listings older than -cache-ttl and exit")
+	cacheTTL := flag.Duration("cache-ttl", cache.DefaultTTL, "Maximum age of cached listings kept by -cache-prune")
+	reportFile := flag.String("report", "", "Write a JSON summary of the run to this file when downloads finish")
+	maxFiles := flag.Int("max-files", 0, "Refuse to download more than this many selected files (0 = no cap)")
+	maxBytes := flag.Int64("max-bytes", 0, "Refuse to download more than this many selected bytes (0 = no cap)")
+	archive := flag.String("archive", "", "Also bundle each batch of downloads into an archive in the output directory: zip or tar.gz")
+	archiveOnly := flag.Bool("archive-only", false, "With -archive, remove the loose files once they are archived")
+	manifest := flag.Bool("manifest", false, "Record every downloaded file (ID, Drive path, local path, size, MD5, time) in manifest.json in -o, merged across runs")
+	maxDuration := flag.Duration("max-duration", 0, "Stop the whole run, listing and downloads, after this long (e.g. 30m) and exit non-zero (0 = no limit)")
+	retryReport := flag.String("retry-report", "", "Download again only the files that failed in a -report from an earlier run (implies -a)")
+	serveAddr := flag.String("serve", "", "While downloading, serve the batch's progress as JSON at /status on this address (e.g. :8080)")
+	summaryFile := flag.String("summary-file", "", "Write the selection summary (Y in the file list) to this file instead of the clipboard")
+	logFile := flag.String("log", "", "Append a JSON-lines record of each finished download to this file")
+	exportMap := flag.String("export-map", "", "Formats to export Google Workspace files as (type=format, comma-separated), e.g. document=pdf,spreadsheet=csv; defaults: document=docx, spreadsheet=xlsx, presentation=pptx, drawing=pdf")
+	nameTemplate := flag.String("name-template", "", "Go template for downloaded file names, e.g. '{{.ModifiedTime.Format \"2006-01-02\"}} {{.Name}}' (fields: Name, Path, ID, FolderID, RootFolderName, Owner, Size, MimeType, CreatedTime, ModifiedTime)")
+	outputStructure := flag.String("output-structure", "mirror", "How downloads are arranged under -o: mirror (Drive folders), flat (all in -o; same-named files collide, see -on-exist rename), date (year/month of modification), or type (images, videos, audio, documents, archives, other)")
+	flattenNames := flag.Bool("flatten-names", false, "Save every file directly in -o, with its folder path (after -output-structure) in its name, e.g. sub__folder__file.jpg")
+	flattenDelimiter := flag.String("flatten-delimiter", drive.DefaultFlattenDelimiter, "What -flatten-names puts between folder and file names")
+	onExist := flag.String("on-exist", "skip", "What to do when a file already exists: skip (same size), overwrite, rename, or newer")
+	confirmOverwrite := flag.Bool("confirm-overwrite", false, "Ask before overwriting a local file whose size differs from Drive's (-on-exist overwrite, -sync, -force); with -file, pass -yes to overwrite")
+	recheckSize := flag.Bool("recheck-size", false, "Before re-downloading a file whose local copy has another size, ask Drive for its current size in case the (cached) listing is out of date")
+	assumeYes := flag.Bool("yes", false, "With -confirm-overwrite and -file, overwrite changed local files without asking")
+	maxRetries := flag.Int("max-retries", drive.DefaultMaxRetries, "Times a rate-limited or failed API call is retried (0 = no retries)")
+	retryBaseDelay := flag.Duration("retry-base-delay", drive.DefaultRetryBaseDelay, "Backoff before the first retry; it doubles on each retry, with random jitter")
+	maxConnections := flag.Int("max-connections", 0, "Maximum requests open at once across folder listings and downloads, whatever -c and -segments allow (0 = no limit)")
+	pageSize := flag.Int("page-size", drive.DefaultPageSize, "Files requested per folder listing call (1-1000); lower it on flaky connections")
+	segments := flag.Int("segments", 1, "Download large files as this many concurrent byte ranges (1 = disabled)")
+	segmentThreshold := flag.Int64("segment-threshold", drive.DefaultSegmentThreshold, "Minimum file size in bytes for segmented downloads")
+	themeName := flag.String("theme", "dark", "Color theme: dark, light, or mono (NO_COLOR forces mono)")
+	quiet := flag.Bool("quiet", false, "Only log errors")
+	verbose := flag.Bool("verbose", false, "Log API calls and skip decisions (redirect stderr to keep them while the TUI runs)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [folder URL...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	// Get API key from flag or environment
-	key := *apiKey
-	if key == "" {
-		key = os.Getenv("GOOGLE_API_KEY")
+	setupLogging(*quiet, *verbose)
+
+	// Apply the color theme before anything is rendered
+	if os.Getenv("NO_COLOR") != "" {
+		*themeName = "mono"
+	}
+	theme, err := tui.ThemeByName(*themeName)
+	if err != nil {
+		fatal("invalid theme", err)
+	}
+	tui.ApplyTheme(theme)
+
+	existPolicy, err := drive.ParseExistPolicy(*onExist)
+	if err != nil {
+		fatal("invalid -on-exist", err)
+	}
+	if *syncMode && *retryReport != "" {
+		fatal("-retry-report cannot be combined with -sync", fmt.Errorf("a retry doesn't list the folders"))
+	}
+	if *syncMode {
+		// Sync decides what is stale itself, so a changed file must replace its old copy
+		*downloadAll = true
+		existPolicy = drive.ExistOverwrite
+	}
+
+	exports, err := drive.ParseExportMap(*exportMap)
+	if err != nil {
+		fatal("invalid -export-map", err)
+	}
+
+	var names *template.Template
+	if *nameTemplate != "" {
+		names, err = drive.ParseNameTemplate(*nameTemplate)
+		if err != nil {
+			fatal("invalid -name-template", err)
+		}
+	}
+
+	structure, err := drive.ParseOutputStructure(*outputStructure)
+	if err != nil {
+		fatal("invalid -output-structure", err)
+	}
+	flattenDelim := ""
+	if *flattenNames {
+		flattenDelim, err = drive.ParseFlattenDelimiter(*flattenDelimiter)
+		if err != nil {
+			fatal("invalid -flatten-delimiter", err)
+		}
+	}
+
+	archiveFormat, err := tui.ParseArchiveFormat(*archive)
+	if err != nil {
+		fatal("invalid -archive", err)
+	}
+	if *archiveOnly && archiveFormat == tui.ArchiveNone {
+		fatal("-archive-only requires -archive", fmt.Errorf("no archive format given"))
+	}
+
+	globs, err := drive.ParseGlobs(*globFlag)
+	if err != nil {
+		fatal("invalid -glob", err)
+	}
+
+	var since time.Time
+	if *sinceFlag != "" {
+		since, err = drive.ParseSince(*sinceFlag, time.Now())
+		if err != nil {
+			fatal("invalid -since", err)
+		}
+	}
+
+	var mimes drive.MimeFilter
+	if mimes.Allow, err = readMimeTypes(*mimeAllowFile); err != nil {
+		fatal("invalid -mime-allow-file", err)
+	}
+	if mimes.Deny, err = readMimeTypes(*mimeDenyFile); err != nil {
+		fatal("invalid -mime-deny-file", err)
+	}
+	filter := fileFilter{terms: splitTerms(*searchTerms), nameOnly: *nameOnly, globs: globs, since: since, mimes: mimes}
+
+	// Folder links may also be given as arguments, on top of any from -f
+	argLinks, err := parseLinkArgs(flag.Args())
+	if err != nil {
+		fatal("invalid argument", err)
+	}
+	if *myDrive {
+		argLinks = append(argLinks, drive.MyDriveLink)
+	}
+
+	scopes, err := drive.ParseScopes(*scope)
+	if err != nil {
+		fatal("invalid -scope", err)
+	}
+
+	// Cache maintenance commands run without authenticating
+	if *cacheInfo || *cacheClear || *cachePrune {
+		if err := runCacheCommand(*cacheInfo, *cacheClear, *cachePrune, *cacheTTL); err != nil {
+			fatal("cache command failed", err)
+		}
+		return
+	}
+
+	httpClient, err := drive.NewHTTPClient(*proxy)
+	if err != nil {
+		fatal("invalid -proxy", err)
+	}
+	ctx := context.Background()
+	if *maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *maxDuration)
+		defer cancel()
+	}
+	clientOpts := []drive.Option{
+		drive.WithHTTPClient(httpClient),
+		drive.WithPageSize(*pageSize),
+		drive.WithRetries(*maxRetries),
+		drive.WithRetryBaseDelay(*retryBaseDelay),
+		drive.WithMaxConnections(*maxConnections),
 	}
 
-	// Determine auth method and create client BEFORE starting TUI
+	// Offline mode browses the cache only, so there is nothing to authenticate
+	var client *drive.Client
+	if *offline {
+		if *exportLinks || *tree || *syncMode || *verify || *singleFile != "" || *retryReport != "" {
+			fatal("-offline cannot be combined with -export-links, -tree, -sync, -verify, -file, or -retry-report", fmt.Errorf("network access required"))
+		}
+	} else {
+		// Get API key from flag, environment, or credentials file, in that order
+		key := *apiKey
+		if key == "" {
+			key = os.Getenv("GOOGLE_API_KEY")
+		}
+		if key == "" {
+			if key, err = apiKeyFromCredentialsFile(); err != nil {
+				slog.Warn("ignoring credentials file", "err", err)
+			}
+		}
+
+		// An API key can't see My Drive, so say so before listing anything
+		if *myDrive && *serviceAccount == "" && !*useOAuth && key != "" {
+			fatal("-root needs -oauth or -service-account", drive.ErrMyDriveNeedsAuth)
+		}
+
+		// Create the client BEFORE starting TUI
+		if *serviceAccount != "" {
+			clientOpts = append(clientOpts, drive.WithServiceAccount(*serviceAccount, scopes...))
+			slog.Info("Authenticating with Google Drive (service account)...", "key", *serviceAccount)
+			client, err = drive.NewClient(ctx, clientOpts...)
+			if err != nil {
+				fatal("authentication failed", err)
+			}
+		} else {
+			client = authenticate(ctx, *useOAuth, key, *credentialsFile, scopes, clientOpts)
+		}
+		client.SetExistPolicy(existPolicy)
+		client.SetExportMap(exports)
+		client.SetNameTemplate(names)
+		client.SetOutputStructure(structure)
+		client.SetFlattenNames(flattenDelim)
+		client.SetDedupeByContent(*dedupeMD5)
+		client.SetRecheckSize(*recheckSize)
+		if err := client.SetSegmentedDownload(*segments, *segmentThreshold); err != nil {
+			fatal("invalid -segment-threshold", err)
+		}
+		if *confirmOverwrite && *singleFile != "" {
+			// No one to ask without the TUI: -yes answers for the user
+			client.SetOverwriteConfirm(func(_ context.Context, file drive.DriveFile, localPath string) bool {
+				if !*assumeYes {
+					slog.Warn("keeping changed local file, pass -yes to overwrite", "file", file.DisplayName(), "path", localPath)
+				}
+				return *assumeYes
+			})
+		}
+		defer logAPICalls(client)
+	}
+
+	if *exportLinks {
+		links, err := collectLinks(*linksFile, argLinks)
+		if err != nil {
+			fatal("-export-links needs folder links", err)
+		}
+		err = runExportLinks(ctx, client, links, filter, *maxConcurrent)
+		if errors.Is(err, errNoFiles) {
+			slog.Warn(err.Error())
+			os.Exit(exitNoFiles)
+		}
+		if err != nil {
+			fatal("listing failed", err)
+		}
+		return
+	}
+
+	if *tree {
+		links, err := collectLinks(*linksFile, argLinks)
+		if err != nil {
+			fatal("-tree needs folder links", err)
+		}
+		err = runTree(ctx, client, links, filter, *maxConcurrent)
+		if errors.Is(err, errNoFiles) {
+			slog.Warn(err.Error())
+			os.Exit(exitNoFiles)
+		}
+		if err != nil {
+			fatal("listing failed", err)
+		}
+		return
+	}
+
+	if *toStdout && *singleFile == "" {
+		fatal("-stdout requires -file", fmt.Errorf("no file given"))
+	}
+	if *revision != "" && *singleFile == "" {
+		fatal("-revision requires -file", fmt.Errorf("no file given"))
+	}
+	if *singleFile != "" {
+		if err := runSingleFile(ctx, client, *singleFile, *revision, *destDir, *toStdout, *manifest); err != nil {
+			fatal("download failed", client.WithQuotaAdvice(err))
+		}
+		return
+	}
+
+	if *verify {
+		links, err := collectLinks(*linksFile, argLinks)
+		if err != nil {
+			fatal("-verify needs folder links", err)
+		}
+		err = runVerify(ctx, client, links, filter, *destDir, *maxConcurrent)
+		switch {
+		case errors.Is(err, errNoFiles):
+			slog.Warn(err.Error())
+			os.Exit(exitNoFiles)
+		case errors.Is(err, errVerifyFailed):
+			slog.Warn(err.Error() + "; re-run without -verify to download them again (-on-exist overwrite replaces mismatches)")
+			os.Exit(1)
+		case err != nil:
+			fatal("listing failed", err)
+		}
+		return
+	}
+
+	// A retry downloads the failures of an earlier report instead of listing folders
+	var retry []drive.DriveFile
+	if *retryReport != "" {
+		retry, err = retryFiles(ctx, client, *retryReport)
+		switch {
+		case errors.Is(err, errNothingToRetry):
+			slog.Info(err.Error())
+			return
+		case errors.Is(err, errNoFiles):
+			slog.Warn("none of the failed files could be fetched again")
+			os.Exit(exitNoFiles)
+		case err != nil:
+			fatal("-retry-report failed", err)
+		}
+		*downloadAll = true
+		*linksFile = ""
+		argLinks = nil
+	}
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(*destDir, 0o755); err != nil {
+		fatal("unable to create output directory", err)
+	}
+
+	// Open the download log before starting the TUI so errors are visible
+	var downloadLog *tui.DownloadLog
+	if *logFile != "" {
+		downloadLog, err = tui.OpenDownloadLog(*logFile)
+		if err != nil {
+			fatal("unable to open download log", err)
+		}
+		defer downloadLog.Close()
+	}
+
+	// Links piped on stdin are read up front; the TUI then takes keyboard
+	// input from the terminal instead. Argument links are merged with -f
+	// here too, since the TUI loads a links file by replacing its input.
+	var initialLinks []string
+	programOpts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if *linksFile == "-" {
+		programOpts = append(programOpts, tea.WithInputTTY())
+	}
+	if *linksFile == "-" || len(argLinks) > 0 {
+		initialLinks, err = collectLinks(*linksFile, argLinks)
+		if err != nil {
+			fatal("unable to read links", err)
+		}
+		*linksFile = ""
+	}
+
+	model := tui.NewModelWithClient(client, tui.Options{
+		LinksFile:        *linksFile,
+		Links:            initialLinks,
+		DestDir:          *destDir,
+		MaxConcurrent:    *maxConcurrent,
+		AutoDownload:     *downloadAll,
+		SearchTerms:      *searchTerms,
+		DownloadTimeout:  *timeout,
+		DownloadLog:      downloadLog,
+		ExistPolicy:      existPolicy,
+		SearchNameOnly:   *nameOnly,
+		ReportPath:       *reportFile,
+		Sync:             *syncMode,
+		Force:            *force,
+		Offline:          *offline,
+		OnlyMissing:      *onlyMissing,
+		Browse:           *browse,
+		HidePaths:        !*showPaths,
+		ConfirmOverwrite: *confirmOverwrite,
+		Files:            retry,
+		Since:            since,
+		Globs:            globs,
+		MimeFilter:       mimes,
+		SummaryPath:      *summaryFile,
+		StatusAddr:       *serveAddr,
+		Archive:          archiveFormat,
+		ArchiveOnly:      *archiveOnly,
+		Manifest:         *manifest,
+		MaxFiles:         *maxFiles,
+		MaxBytes:         *maxBytes,
+		Context:          ctx,
+	})
+	p := tea.NewProgram(model, programOpts...)
+
+	detachLoggingFromTerminal()
+	finalModel, err := p.Run()
+	setupLogging(*quiet, *verbose)
+	if err != nil {
+		fatal("error running program", err)
+	}
+
+	// Exit non-zero when any download failed or a cap was hit so scripts can tell
+	if m, ok := finalModel.(tui.Model); ok && m.HasFailures() {
+		if err := m.CapError(); err != nil {
+			slog.Error("download refused", "err", err)
+		}
+		downloadLog.Close()
+		logAPICalls(client)
+		os.Exit(1)
+	} else if ok && m.NoFiles() {
+		downloadLog.Close()
+		logAPICalls(client)
+		os.Exit(exitNoFiles)
+	}
+}
+
+// logAPICalls reports in verbose mode how many API calls the run made, to
+// help tune -page-size and -c against the quota
+func logAPICalls(client *drive.Client) {
+	if client != nil {
+		slog.Debug("finished", "apiCalls", client.APICalls())
+	}
+}
+
+// runCacheCommand performs the -cache-clear, -cache-prune, and -cache-info commands
+func runCacheCommand(info, clear, prune bool, ttl time.Duration) error {
+	mgr, err := cache.NewManager()
+	if err != nil {
+		return fmt.Errorf("unable to open cache: %w", err)
+	}
+
+	if clear {
+		if err := mgr.Clear(); err != nil {
+			return fmt.Errorf("unable to clear cache: %w", err)
+		}
+		fmt.Println("Cache cleared")
+	}
+
+	if prune {
+		removed, err := mgr.Prune(ttl)
+		if err != nil {
+			return fmt.Errorf("unable to prune cache: %w", err)
+		}
+		fmt.Printf("Pruned %d cached listings older than %v\n", removed, ttl)
+	}
+
+	if info {
+		st := mgr.Stats()
+		fmt.Printf("Cache file:     %s\n", mgr.Path())
+		fmt.Printf("Folders cached: %d\n", st.Folders)
+		fmt.Printf("Files cached:   %d\n", st.Files)
+		fmt.Printf("Total size:     %d bytes\n", st.TotalBytes)
+		if st.Folders > 0 {
+			fmt.Printf("Oldest entry:   %s\n", st.OldestFetch.Format(time.RFC3339))
+			fmt.Printf("Newest entry:   %s\n", st.NewestFetch.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// authenticate creates a Drive client with OAuth, or with key when OAuth isn't
+// forced and a key is available. It exits with instructions if neither works.
+func authenticate(ctx context.Context, useOAuth bool, key, credentialsFile string, scopes []string, clientOpts []drive.Option) *drive.Client {
 	var client *drive.Client
 	var err error
-	ctx := context.Background()
 
 	// If --oauth flag is set, or no API key available, use OAuth
-	if *useOAuth || key == "" {
-		// Check if credentials file exists
-		if _, err := os.Stat(*credentialsFile); os.IsNotExist(err) {
-			if *useOAuth {
-				fmt.Printf("Error: credentials file not found: %s\n", *credentialsFile)
+	if useOAuth || key == "" {
+		// Check if credentials file exists, or was passed in the environment
+		if _, err := os.Stat(credentialsFile); os.IsNotExist(err) && os.Getenv(drive.CredentialsEnv) == "" {
+			if useOAuth {
+				fmt.Printf("Error: credentials file not found: %s\n", credentialsFile)
 				fmt.Println("Specify path with: ./gdrive-dl --oauth -credentials /path/to/credentials.json")
 				os.Exit(1)
 			}
@@ -53,43 +504,31 @@ func main() {
 			fmt.Println("Option 1 - OAuth (recommended, avoids quota issues):")
 			fmt.Println("  Place credentials.json in the current directory")
 			fmt.Println("  Or specify path: ./gdrive-dl --oauth -credentials /path/to/credentials.json")
+			fmt.Println("  Or set " + drive.CredentialsEnv + " (and " + drive.TokenEnv + ") to the JSON contents")
 			fmt.Println()
 			fmt.Println("Option 2 - API Key (simpler but has quota limits):")
 			fmt.Println("  ./gdrive-dl -k YOUR_API_KEY")
 			fmt.Println("  export GOOGLE_API_KEY=YOUR_API_KEY")
 			fmt.Println("  Or add to .env: GOOGLE_API_KEY=YOUR_API_KEY")
+			fmt.Println("  Or add to ~/.config/gdrive-dl/credentials: api_key=YOUR_API_KEY")
 			os.Exit(1)
 		}
 
 		// Authenticate with OAuth BEFORE starting TUI
-		fmt.Println("Authenticating with Google Drive (OAuth)...")
-		client, err = drive.NewClientWithOAuth(ctx, *credentialsFile)
+		slog.Info("Authenticating with Google Drive (OAuth)...", "credentials", credentialsFile)
+		client, err = drive.NewClient(ctx, append(clientOpts, drive.WithOAuth(credentialsFile, scopes...))...)
 		if err != nil {
-			fmt.Printf("Error authenticating: %v\n", err)
-			os.Exit(1)
+			fatal("authentication failed", err)
 		}
-		fmt.Println("Authentication successful!")
+		slog.Info("Authentication successful!")
 	} else {
 		// Use API key
-		fmt.Println("Authenticating with Google Drive (API Key)...")
-		client, err = drive.NewClientWithAPIKey(ctx, key)
+		slog.Info("Authenticating with Google Drive (API Key)...")
+		client, err = drive.NewClient(ctx, append(clientOpts, drive.WithAPIKey(key))...)
 		if err != nil {
-			fmt.Printf("Error authenticating: %v\n", err)
-			os.Exit(1)
+			fatal("authentication failed", err)
 		}
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(*destDir, 0o755); err != nil {
-		fmt.Printf("Error creating output directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	model := tui.NewModelWithClient(client, *linksFile, *destDir, *maxConcurrent, *downloadAll, *searchTerms)
-	p := tea.NewProgram(model, tea.WithAltScreen())
-
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
-	}
+	return client
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"google-drive-dl/drive"
+)
+
+// treeNode is a folder in the tree printed by -tree
+type treeNode struct {
+	name    string
+	folders map[string]*treeNode
+	files   []drive.DriveFile
+	// count and bytes total every file beneath the folder
+	count int
+	bytes int64
+}
+
+func newTreeNode(name string) *treeNode {
+	return &treeNode{name: name, folders: make(map[string]*treeNode)}
+}
+
+// buildTree arranges files into folders by their root folder and Path
+func buildTree(files []drive.DriveFile) *treeNode {
+	root := newTreeNode("")
+	for _, f := range files {
+		node := root
+		segments := strings.Split(f.Path, "/")
+		if rootName := cmp.Or(f.RootFolderName, f.RootFolderID); rootName != "" {
+			segments = append([]string{rootName}, segments...)
+		}
+		node.count++
+		node.bytes += f.Size
+		for _, seg := range segments {
+			if seg == "" {
+				continue
+			}
+			child, ok := node.folders[seg]
+			if !ok {
+				child = newTreeNode(seg)
+				node.folders[seg] = child
+			}
+			node = child
+			node.count++
+			node.bytes += f.Size
+		}
+		node.files = append(node.files, f)
+	}
+	return root
+}
+
+// printTree writes node's folders, then its files, indented by depth.
+// Folders show the number and total size of the files beneath them.
+func printTree(w io.Writer, node *treeNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	names := make([]string, 0, len(node.folders))
+	for name := range node.folders {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	for _, name := range names {
+		child := node.folders[name]
+		fmt.Fprintf(w, "%s%s/ (%d files, %s)\n", indent, child.name, child.count, drive.FormatSize(child.bytes))
+		printTree(w, child, depth+1)
+	}
+
+	files := slices.Clone(node.files)
+	slices.SortFunc(files, func(a, b drive.DriveFile) int {
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	for _, f := range files {
+		fmt.Fprintf(w, "%s%s (%s)\n", indent, f.Name, drive.FormatSize(f.Size))
+	}
+}
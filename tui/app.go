@@ -1,10 +1,15 @@
 package tui
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +17,7 @@ import (
 	"google-drive-dl/cache"
 	"google-drive-dl/drive"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -35,6 +41,8 @@ const (
 	ViewDownloading
 	// ViewDone shows the final download summary.
 	ViewDone
+	// ViewDestDir lets users change the download directory before downloading.
+	ViewDestDir
 )
 
 // SortField represents which field to sort the file list by.
@@ -47,6 +55,10 @@ const (
 	SortBySize
 	// SortByDate sorts files by their modification date.
 	SortByDate
+	// SortByFolder groups files by the top-level folder link they came from.
+	SortByFolder
+	// SortByOwner groups files by owner; files with no known owner sort first.
+	SortByOwner
 )
 
 // Model is the main TUI application state. It implements the Bubble Tea Model
@@ -56,10 +68,20 @@ type Model struct {
 	width         int
 	height        int
 	err           error
+	status        string // informational message, cleared on the next key press
 	linksFile     string
 	destDir       string
 	maxConcurrent int
 
+	// downloadTimeout bounds each individual file download (0 = no limit)
+	downloadTimeout time.Duration
+
+	// downloadLog records each finished download (nil = disabled)
+	downloadLog *DownloadLog
+
+	// existPolicy decides when a local file counts as already downloaded
+	existPolicy drive.ExistPolicy
+
 	// Drive client
 	driveClient *drive.Client
 
@@ -67,16 +89,27 @@ type Model struct {
 	cacheManager *cache.Manager
 	cachedAt     map[string]time.Time // folder ID -> when it was cached
 	fromCache    bool                 // whether current files are from cache
+	// batchID is the batchKey of the running batch, taken before resumeBatch
+	// leaves any files out
+	batchID string
+	// batchState records the files the running batch finished, to resume it
+	batchState *cache.BatchState
 
 	// Links input
 	linksInput textarea.Model
 	links      []string
 
+	// Destination directory input, and the view to return to when done
+	destInput      textinput.Model
+	destReturnView View
+
 	// Search
-	searchInput textinput.Model
-	searchTerms []string
+	searchInput    textinput.Model
+	searchTerms    []string
+	searchNameOnly bool // match terms against file names only, not folder paths
 
 	// Files
+	listing       bool // a streamed listing is in progress
 	allFiles      []drive.DriveFile
 	filteredFiles []drive.DriveFile
 	selectedFiles map[string]bool
@@ -85,15 +118,29 @@ type Model struct {
 	sortField     SortField
 	sortAsc       bool
 	lastKeyG      bool // Track if last key was 'g' for gg command
+	countPrefix   int  // digits typed before a count command like 10t
+	listCursor    int  // fileCursor in the full list, restored when leaving search
+
+	// Folders that failed during the last listing, shown until dismissed with Esc
+	listWarnings []string
 
 	// Info popup
 	showInfoPopup bool
+	revisions     revisionPicker
+
+	// File type breakdown popup (toggled with b)
+	showTypesPopup bool
 
 	// File existence cache - maps file ID to whether it exists locally
-	fileExistsCache map[string]bool
+	fileExistsCache map[string]drive.LocalState
 
 	// Dedupe mode - show only smallest version of duplicate files
-	showDeduped          bool
+	showDeduped bool
+
+	// Browse view - the file list shows one folder at a time; browsePath is
+	// the current folder's location (see fileLocation), "" for the roots
+	browsing             bool
+	browsePath           string
 	dedupedFiles         []drive.DriveFile
 	dedupedFilteredFiles []drive.DriveFile
 
@@ -101,14 +148,107 @@ type Model struct {
 	fileProgress     map[string]drive.DownloadProgress
 	downloading      bool
 	downloadDone     bool
-	completedCount   int
-	totalToDownload  int
 	progressMu       *sync.Mutex
-	downloadingFiles []drive.DriveFile // Files currently being downloaded
-
-	// Auto-download mode
+	batch            *drive.BatchTracker // overall progress, guarded by progressMu
+	downloadingFiles []drive.DriveFile   // Files currently being downloaded
+	batchStarted     time.Time           // when startDownload fired
+	batchFinished    time.Time           // when downloadCompleteMsg arrived
+
+	// Download queue - files not yet picked up by a worker, which can be
+	// reordered or removed from the Downloading view, and the files being
+	// downloaded, which can be cancelled one at a time. queueCursor runs
+	// over the files in progress and then the queue.
+	queue       *downloadQueue
+	active      *activeDownloads
+	queueCursor int
+	queueFilter textinput.Model
+	failedFocus string        // ID of the failed file ]/[ last jumped to, if any
+	pool        *adaptivePool // lowers concurrency while Drive reports quota errors
+
+	// Questions from workers before overwriting changed files (-confirm-overwrite)
+	confirmOverwrite bool
+	prompts          *overwritePrompts
+
+	// Serves the batch's progress at /status while it downloads (-serve)
+	statusAddr   string
+	statusServer *http.Server
+
+	// Terminal window title last set, so progress can be followed from the taskbar
+	windowTitle string
+
+	// Graceful shutdown - set once the user cancels a running download
+	cancelling bool
+	cancelled  bool
+
+	// Completion report written when downloads finish
+	reportPath  string
+	reportError error
+
+	// Auto-download mode; presetFiles replace the listing when set
 	autoDownload    bool
 	autoSearchTerms string
+	presetFiles     []drive.DriveFile
+
+	// Re-download existing files instead of skipping them (toggled with x)
+	forceDownload bool
+
+	// Offline mode - browse cached listings only, no API calls or downloads
+	offline bool
+
+	// Hide files already downloaded from the lists (toggled with h)
+	hideExisting bool
+
+	// Selection saved by an earlier run, offered for restoring once per run
+	selectionOffered bool
+	savedSelection   *cache.Selection
+
+	// Hide files modified before since, unless it is zero (set with m)
+	since      time.Time
+	sinceInput textinput.Model
+	// Hide files whose names match none of globs, unless it is empty, and
+	// files of MIME types mimes drops
+	globs []string
+	mimes drive.MimeFilter
+
+	// Where Y writes the selection summary ("" = clipboard)
+	summaryPath string
+
+	// Show file names without their Drive path in the flat list (p toggles)
+	hidePaths bool
+
+	// Archive bundling - each batch is written to archivePath as well as, or
+	// with archiveOnly instead of, loose files
+	archiveFormat ArchiveFormat
+	archiveOnly   bool
+	archivePath   string
+	archiveErrors []string
+
+	// Done screen file list, filtered to failed or skipped files and scrolled
+	// with doneCursor
+	doneFilter doneFilter
+	doneCursor int
+
+	// Manifest - each batch's downloads are merged into manifest.json in the
+	// output directory; manifestError is why the last update failed
+	manifest      bool
+	manifestError error
+
+	// Safety caps on the selected set (0 = no cap); capExceeded is set when an
+	// automatic download was refused because of them, and capErr says why
+	maxFiles    int
+	maxBytes    int64
+	capExceeded bool
+	capErr      error
+
+	// The last listing found no files at all
+	noFiles bool
+
+	// Sync mode - download only files changed since the cached listing
+	syncMode        bool
+	deletedUpstream []cache.CachedFile
+
+	// The run's deadline passed before it finished
+	timedOut bool
 
 	// Context for cancellation
 	ctx    context.Context
@@ -117,59 +257,201 @@ type Model struct {
 
 // Messages
 type (
-	errMsg              struct{ err error }
-	filesLoadedMsg      struct{ files []drive.DriveFile }
+	errMsg         struct{ err error }
+	filesLoadedMsg struct {
+		files      []drive.DriveFile
+		previous   []cache.CachedFile // cached listing it replaced, for -sync
+		warning    error              // some folders failed, files is incomplete
+		duplicates int                // files found under more than one link, dropped
+	}
 	downloadProgressMsg drive.DownloadProgress
-	downloadCompleteMsg struct{ errors []string }
-	tickMsg             struct{}
-	filesFromCacheMsg   struct {
+	downloadCompleteMsg struct {
+		errors        []string
+		archiveErrors []string
+		manifestError error
+	}
+	tickMsg            struct{}
+	shutdownTimeoutMsg struct{}
+	filesFromCacheMsg  struct {
 		files    []drive.DriveFile
 		cachedAt map[string]time.Time
 	}
 )
+
+// filesPageMsg delivers one page of a streamed listing along with the channels
+// needed to wait for the next one
+type filesPageMsg struct {
+	files  []drive.DriveFile
+	pages  <-chan []drive.DriveFile
+	result <-chan tea.Msg
+}
+
 type refreshCompleteMsg struct {
 	files []drive.DriveFile
 }
 
 func (e errMsg) Error() string { return e.err.Error() }
 
+// Options configures a Model created by NewModelWithClient.
+type Options struct {
+	// LinksFile is an optional file of folder links to pre-fill the links input
+	LinksFile string
+	// Links pre-fills the links input directly, e.g. with links read from stdin
+	Links []string
+	// DestDir is the directory files are downloaded into
+	DestDir string
+	// MaxConcurrent is the maximum number of parallel downloads
+	MaxConcurrent int
+	// AutoDownload downloads all matching files without a selection prompt
+	AutoDownload bool
+	// SearchTerms is a comma-separated list of terms used by AutoDownload
+	SearchTerms string
+	// DownloadTimeout bounds each individual file download (0 = no limit)
+	DownloadTimeout time.Duration
+	// DownloadLog, if set, receives a JSON line for every finished download
+	DownloadLog *DownloadLog
+	// SearchNameOnly restricts search terms to file names, ignoring folder paths
+	SearchNameOnly bool
+	// ExistPolicy must match the policy set on the client; it drives the "exists" indicator
+	ExistPolicy drive.ExistPolicy
+	// ReportPath, if set, receives a JSON summary of the run when downloads finish
+	ReportPath string
+	// Sync lists the folders afresh and downloads only files that are new or
+	// changed since the cached listing, reporting files deleted upstream.
+	// It requires AutoDownload.
+	Sync bool
+	// Force re-downloads files that already exist locally instead of skipping them
+	Force bool
+	// OnlyMissing starts with files that already exist locally hidden
+	OnlyMissing bool
+	// Since, if not zero, lists only files modified at or after it; files
+	// without a modified time are left out
+	Since time.Time
+	// MimeFilter lists only files of the MIME types it keeps
+	MimeFilter drive.MimeFilter
+	// SummaryPath, if set, is where Y writes the selection summary instead
+	// of the clipboard
+	SummaryPath string
+	// Globs, if set, lists only files whose names match one of the patterns
+	Globs []string
+	// Archive bundles each batch of downloads into a zip or tar.gz in the
+	// output directory; ArchiveOnly removes the loose files once archived
+	Archive     ArchiveFormat
+	ArchiveOnly bool
+	// Manifest merges every downloaded file into manifest.json in the output
+	// directory, an index that accumulates across runs. Archive-only runs
+	// leave no loose files to index, so they don't update it.
+	Manifest bool
+	// Offline loads listings only from the cache and disables downloads; the
+	// client may be nil
+	Offline bool
+	// MaxFiles and MaxBytes refuse downloads whose selection exceeds them (0 = no cap)
+	MaxFiles int
+	MaxBytes int64
+	// Browse starts the file list in the folder-by-folder browse view
+	Browse bool
+	// ConfirmOverwrite asks before the overwrite policy (or Force) replaces a
+	// local copy whose size differs from Drive's
+	ConfirmOverwrite bool
+	// StatusAddr, if set, is the address a JSON Status of each batch is served
+	// at, under /status, while it downloads
+	StatusAddr string
+	// HidePaths starts the file list showing bare file names; the info popup
+	// still shows each file's path
+	HidePaths bool
+	// Files, if set, are downloaded without listing any folders, e.g. the
+	// failures of an earlier run. It requires AutoDownload.
+	Files []drive.DriveFile
+	// Context, if set, is the parent of everything the run does. When its
+	// deadline passes, listing and downloads stop and the run ends timed out.
+	Context context.Context
+}
+
 // NewModelWithClient creates a new TUI model with a pre-authenticated client
-func NewModelWithClient(client *drive.Client, linksFile, destDir string, maxConcurrent int, autoDownload bool, searchTerms string) Model {
+func NewModelWithClient(client *drive.Client, opts Options) Model {
 	ti := textarea.New()
 	ti.Placeholder = "Paste Google Drive folder links (one per line)..."
 	ti.Focus()
 	ti.SetWidth(80)
 	ti.SetHeight(10)
+	if len(opts.Links) > 0 {
+		ti.SetValue(strings.Join(opts.Links, "\n"))
+	}
 
 	si := textinput.New()
-	si.Placeholder = "Search terms (comma-separated, e.g., 'abc, ddd') - leave empty to see all"
+	si.Placeholder = "Search terms (comma-separated, e.g., 'abc, glob:*.jpg') - leave empty to see all"
 	si.Width = 70
 
-	ctx, cancel := context.WithCancel(context.Background())
+	di := textinput.New()
+	di.Placeholder = "Directory to download files into"
+	di.Width = 70
+
+	qi := textinput.New()
+	qi.Placeholder = "Filter queued files"
+	qi.Prompt = "/"
+	qi.Width = 70
+
+	mi := textinput.New()
+	mi.Placeholder = "2024-01-01, 168h or 7d"
+	mi.Prompt = "Modified since: "
+	mi.Width = 30
+
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
 
 	// Initialize cache manager (ignore errors, cache is optional)
 	cacheMgr, _ := cache.NewManager()
 
 	return Model{
-		view:            ViewLinks,
-		linksInput:      ti,
-		searchInput:     si,
-		selectedFiles:   make(map[string]bool),
-		fileProgress:    make(map[string]drive.DownloadProgress),
-		fileExistsCache: make(map[string]bool),
-		progressMu:      &sync.Mutex{},
-		driveClient:     client,
-		linksFile:       linksFile,
-		destDir:         destDir,
-		maxConcurrent:   maxConcurrent,
-		ctx:             ctx,
-		cancel:          cancel,
-		sortField:       SortByName,
-		sortAsc:         true,
-		autoDownload:    autoDownload,
-		autoSearchTerms: searchTerms,
-		cacheManager:    cacheMgr,
-		cachedAt:        make(map[string]time.Time),
+		view:             ViewLinks,
+		linksInput:       ti,
+		searchInput:      si,
+		destInput:        di,
+		queueFilter:      qi,
+		sinceInput:       mi,
+		selectedFiles:    make(map[string]bool),
+		fileProgress:     make(map[string]drive.DownloadProgress),
+		batch:            drive.NewBatchTracker(nil),
+		fileExistsCache:  make(map[string]drive.LocalState),
+		progressMu:       &sync.Mutex{},
+		driveClient:      client,
+		linksFile:        opts.LinksFile,
+		destDir:          opts.DestDir,
+		maxConcurrent:    opts.MaxConcurrent,
+		downloadTimeout:  opts.DownloadTimeout,
+		downloadLog:      opts.DownloadLog,
+		existPolicy:      opts.ExistPolicy,
+		searchNameOnly:   opts.SearchNameOnly,
+		reportPath:       opts.ReportPath,
+		syncMode:         opts.Sync,
+		forceDownload:    opts.Force,
+		offline:          opts.Offline,
+		hideExisting:     opts.OnlyMissing,
+		browsing:         opts.Browse,
+		since:            opts.Since,
+		globs:            opts.Globs,
+		mimes:            opts.MimeFilter,
+		summaryPath:      opts.SummaryPath,
+		statusAddr:       opts.StatusAddr,
+		confirmOverwrite: opts.ConfirmOverwrite,
+		hidePaths:        opts.HidePaths,
+		manifest:         opts.Manifest && !opts.ArchiveOnly,
+		archiveFormat:    opts.Archive,
+		archiveOnly:      opts.ArchiveOnly,
+		maxFiles:         opts.MaxFiles,
+		maxBytes:         opts.MaxBytes,
+		ctx:              ctx,
+		cancel:           cancel,
+		sortField:        SortByName,
+		sortAsc:          true,
+		autoDownload:     opts.AutoDownload,
+		autoSearchTerms:  opts.SearchTerms,
+		presetFiles:      opts.Files,
+		cacheManager:     cacheMgr,
+		cachedAt:         make(map[string]time.Time),
 	}
 }
 
@@ -178,12 +460,17 @@ func NewModelWithClient(client *drive.Client, linksFile, destDir string, maxConc
 func (m Model) Init() tea.Cmd {
 	cmds := []tea.Cmd{
 		textarea.Blink,
+		waitForDeadline(m.ctx),
 	}
 
 	// Load links from file if provided
 	if m.linksFile != "" {
 		cmds = append(cmds, m.loadLinksFromFile())
 	}
+	if len(m.presetFiles) > 0 {
+		files := m.presetFiles
+		cmds = append(cmds, func() tea.Msg { return filesLoadedMsg{files: files} })
+	}
 
 	return tea.Batch(cmds...)
 }
@@ -203,6 +490,10 @@ type linksFileLoadedMsg struct{ content string }
 // Update implements the Bubble Tea Model interface. It handles all incoming
 // messages including keyboard events, window resizes, and async operation results.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.lateAfterTimeout(msg) {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -210,28 +501,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update input widths to use full terminal width
 		m.linksInput.SetWidth(msg.Width - 4)
 		m.searchInput.Width = msg.Width - 4
+		m.destInput.Width = msg.Width - 4
+		m.queueFilter.Width = msg.Width - 4
 		return m, nil
 
 	case tea.KeyMsg:
+		m.status = ""
+
+		// Cancelling an active download is cooperative: workers get a chance
+		// to stop and clean up, and a second press force-quits.
+		if m.view == ViewDownloading && !m.downloadDone {
+			if m.queueFilter.Focused() && msg.String() != "ctrl+c" {
+				return m.updateDownloading(msg)
+			}
+			switch msg.String() {
+			case "ctrl+c", "esc", "q":
+				if m.cancelling {
+					return m, m.quit()
+				}
+				return m.beginShutdown()
+			}
+		}
+
+		// The modified-since prompt takes every key but ctrl+c while open
+		if m.sinceInput.Focused() && msg.String() != "ctrl+c" {
+			return m.updateSince(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			m.cancel()
-			return m, tea.Quit
+			return m, m.quit()
 		case "q":
 			// Quit from any view except text input views (Links, Search)
 			switch m.view {
 			case ViewFileList, ViewFiles, ViewDownloading, ViewDone:
 				m.cancel()
-				return m, tea.Quit
+				return m, m.quit()
 			}
 		case "esc":
-			// If info popup is open, let the view handler close it
-			if m.showInfoPopup {
+			// If a popup is open, let the view handler close it
+			if m.showInfoPopup || m.showTypesPopup {
 				break
 			}
-			if m.view == ViewDownloading {
-				m.cancel()
-				return m, tea.Quit
+			// Decline a restore prompt before leaving the file list
+			if m.view == ViewFileList && m.savedSelection != nil {
+				m.answerRestorePrompt(false)
+				return m, nil
+			}
+			// Dismiss the listing warnings before leaving the file list
+			if m.view == ViewFileList && len(m.listWarnings) > 0 {
+				m.listWarnings = nil
+				return m, nil
+			}
+			// Leave a subfolder of the browse view before the file list
+			if m.view == ViewFileList && m.browsing && m.browseUp() {
+				return m, nil
 			}
 			// Go back
 			switch m.view {
@@ -240,9 +565,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.linksInput.Focus()
 			case ViewSearch:
 				m.view = ViewFileList
+				rows, _ := m.listRows(m.getDisplayFiles())
+				m.fileCursor = min(m.listCursor, max(len(rows)-1, 0))
 			case ViewFiles:
 				m.view = ViewSearch
 				m.searchInput.Focus()
+			case ViewDestDir:
+				m.view = m.destReturnView
+				m.destInput.Blur()
 			}
 			return m, nil
 		}
@@ -251,7 +581,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.linksInput.SetValue(msg.content)
 		return m, nil
 
+	case revisionsLoadedMsg:
+		m.revisionsLoaded(msg)
+		return m, nil
+
 	case errMsg:
+		m.listing = false
 		m.err = msg.err
 		return m, nil
 
@@ -259,6 +594,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.allFiles = msg.files
 		m.cachedAt = msg.cachedAt
 		m.fromCache = true
+		m.noFiles = false
 		m.sortFiles()
 		m.updateFileExistsCache()
 
@@ -274,14 +610,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 				m.searchTerms = cleanTerms
-				m.filteredFiles = drive.FilterFiles(m.allFiles, cleanTerms)
+				m.filteredFiles = m.filterFiles(cleanTerms)
 			} else {
 				m.filteredFiles = m.allFiles
 			}
+			m.filteredFiles = m.listFilters(m.filteredFiles)
 
 			if len(m.filteredFiles) == 0 {
 				m.err = fmt.Errorf("no files match the search terms")
 				m.view = ViewDone
+				m.reportError = m.writeReport()
 				return m, nil
 			}
 
@@ -295,14 +633,48 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.view = ViewFileList
 		m.fileCursor = 0
+		m.offerSavedSelection()
 		return m, nil
 
+	case filesPageMsg:
+		// First page of a new listing replaces whatever was shown before
+		if !m.listing {
+			m.listing = true
+			m.allFiles = nil
+			m.fromCache = false
+			m.fileCursor = 0
+		}
+		m.allFiles = append(m.allFiles, msg.files...)
+		m.sortFiles()
+		for _, f := range msg.files {
+			m.fileExistsCache[f.ID] = m.checkFileExistsLocally(f)
+		}
+		m.refreshDerivedLists()
+		if !m.autoDownload && m.view == ViewLinks {
+			m.view = ViewFileList
+		}
+		return m, waitForListing(msg.pages, msg.result)
+
 	case filesLoadedMsg:
+		streamed := m.listing
+		m.listing = false
 		m.allFiles = msg.files
 		m.fromCache = false
+		m.noFiles = len(msg.files) == 0
+		m.listWarnings = nil
+		if msg.warning != nil {
+			m.listWarnings = strings.Split(msg.warning.Error(), "; ")
+		}
+		if msg.duplicates > 0 {
+			m.status = fmt.Sprintf("Collapsed %d duplicate files found under more than one link", msg.duplicates)
+		}
 		m.sortFiles()
 		m.updateFileExistsCache()
 
+		if m.noFiles {
+			return m.showNoFiles()
+		}
+
 		// Cache is already saved in loadFilesWithCache
 
 		// If auto-download mode is enabled, filter and download immediately
@@ -318,14 +690,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 				m.searchTerms = cleanTerms
-				m.filteredFiles = drive.FilterFiles(m.allFiles, cleanTerms)
+				m.filteredFiles = m.filterFiles(cleanTerms)
 			} else {
 				m.filteredFiles = m.allFiles
 			}
+			m.filteredFiles = m.listFilters(m.filteredFiles)
+
+			if m.syncMode {
+				// A partial listing would make the missing folders look deleted
+				if msg.warning == nil {
+					m.deletedUpstream = deletedUpstream(m.allFiles, msg.previous)
+				}
+				m.filteredFiles = m.syncChanges(m.filteredFiles, msg.previous)
+				if len(m.filteredFiles) == 0 {
+					m.status = "Already in sync, nothing to download"
+					m.view = ViewDone
+					m.reportError = m.writeReport()
+					return m, nil
+				}
+			}
 
 			if len(m.filteredFiles) == 0 {
 				m.err = fmt.Errorf("no files match the search terms")
 				m.view = ViewDone
+				m.reportError = m.writeReport()
 				return m, nil
 			}
 
@@ -339,32 +727,67 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.startDownload()
 		}
 
+		// Keep the user's place if they were already browsing streamed results
+		if streamed && m.view != ViewLinks {
+			m.refreshDerivedLists()
+			m.offerSavedSelection()
+			return m, nil
+		}
+
 		m.view = ViewFileList
 		m.fileCursor = 0
+		m.offerSavedSelection()
 		return m, nil
 
 	case downloadProgressMsg:
 		prog := drive.DownloadProgress(msg)
 		m.progressMu.Lock()
 		m.fileProgress[prog.FileID] = prog
-		if prog.Done {
-			m.completedCount++
-		}
+		m.batch.Update(prog)
 		m.progressMu.Unlock()
 		return m, nil
 
+	case runTimedOutMsg:
+		return m.timeOut()
+
+	case shutdownTimeoutMsg:
+		// Workers didn't stop within the grace period, give up waiting
+		if !m.downloadDone {
+			return m, m.quit()
+		}
+		return m, nil
+
 	case downloadCompleteMsg:
 		m.downloadDone = true
+		m.stopStatusServer()
+		m.progressMu.Lock()
+		m.finishBatchState(m.batch.Progress())
+		m.progressMu.Unlock()
+		m.batchFinished = time.Now()
+		m.cancelled = m.cancelling
 		m.view = ViewDone
 		m.updateFileExistsCache() // Refresh cache after downloads
 		if len(msg.errors) > 0 {
 			m.err = fmt.Errorf("%d downloads failed", len(msg.errors))
 		}
-		return m, nil
+		m.archiveErrors = msg.archiveErrors
+		m.manifestError = msg.manifestError
+		m.resetDoneList()
+		m.reportError = m.writeReport()
+		report := m.buildReport()
+		m.windowTitle = fmt.Sprintf("Done — %d succeeded, %d failed", report.Succeeded, report.Failed)
+		return m, tea.SetWindowTitle(m.windowTitle)
 
 	case tickMsg:
 		if m.view == ViewDownloading && !m.downloadDone {
-			return m, tea.Tick(100*time.Millisecond, func(_ time.Time) tea.Msg { return tickMsg{} })
+			m.refreshFinishedExists()
+			tick := tea.Tick(100*time.Millisecond, func(_ time.Time) tea.Msg { return tickMsg{} })
+			// Only touch the terminal title when the text actually changes
+			if title := m.downloadTitle(); title != m.windowTitle {
+				m.windowTitle = title
+				return m, tea.Batch(tick, tea.SetWindowTitle(title))
+			}
+			return m, tick
 		}
 		return m, nil
 	}
@@ -379,6 +802,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateSearch(msg)
 	case ViewFiles:
 		return m.updateFiles(msg)
+	case ViewDestDir:
+		return m.updateDestDir(msg)
+	case ViewDownloading:
+		return m.updateDownloading(msg)
+	case ViewDone:
+		return m.updateDone(msg)
 	}
 
 	return m, nil
@@ -403,31 +832,20 @@ func (m Model) updateLinks(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) submitLinks() (tea.Model, tea.Cmd) {
-	if m.driveClient == nil {
+	if m.driveClient == nil && !m.offline {
 		m.err = fmt.Errorf("Drive client not ready yet, please wait...")
 		return m, nil
 	}
 
-	content := m.linksInput.Value()
-	lines := strings.Split(content, "\n")
-	var links []string
-	var invalidLinks []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		// Validate URL by attempting to extract folder ID
-		_, err := drive.ExtractFolderID(line)
-		if err != nil {
-			invalidLinks = append(invalidLinks, line)
-			continue
+	// Stop on lines that aren't links, so a typo doesn't quietly drop a folder
+	links, invalid := drive.ParseLinks(m.linksInput.Value())
+	if len(invalid) > 0 {
+		first := invalid[0]
+		more := ""
+		if len(invalid) > 1 {
+			more = fmt.Sprintf(" (and %d more lines)", len(invalid)-1)
 		}
-		links = append(links, line)
-	}
-
-	if len(invalidLinks) > 0 && len(links) == 0 {
-		m.err = fmt.Errorf("no valid Google Drive folder links found. Invalid: %d", len(invalidLinks))
+		m.err = fmt.Errorf("line %d is not a Google Drive folder link: %s%s; fix it or comment it out with #", first.Line, truncate(first.Text, 60), more)
 		return m, nil
 	}
 
@@ -446,71 +864,139 @@ func (m Model) submitLinks() (tea.Model, tea.Cmd) {
 func (m Model) loadFilesWithCache(forceRefresh bool) tea.Cmd {
 	return func() tea.Msg {
 		// Build a combined cache key from all folder IDs
-		var folderIDs []string
-		for _, link := range m.links {
-			folderID, err := drive.ExtractFolderID(link)
-			if err != nil {
-				continue
-			}
-			folderIDs = append(folderIDs, folderID)
-		}
-
+		folderIDs, cacheKey := folderKey(m.links)
 		if len(folderIDs) == 0 {
 			return errMsg{fmt.Errorf("no valid folder IDs found")}
 		}
 
-		// Sort for consistent cache key
-		sort.Strings(folderIDs)
-		cacheKey := strings.Join(folderIDs, "+")
+		if m.offline {
+			return m.loadOfflineFiles(cacheKey, folderIDs)
+		}
+
+		// Sync mode always lists afresh and diffs against the cached listing
+		var previous []cache.CachedFile
+		if m.syncMode {
+			forceRefresh = true
+			if m.cacheManager != nil {
+				if cached := m.cacheManager.GetFolder(cacheKey); cached != nil {
+					previous = cached.Files
+				}
+			}
+		}
 
 		// If not forcing refresh, try cache first
 		if !forceRefresh && m.cacheManager != nil {
 			cached := m.cacheManager.GetFolder(cacheKey)
 			if cached != nil && len(cached.Files) > 0 {
-				// Convert cached files to drive files
-				var cachedFiles []drive.DriveFile
-				for _, cf := range cached.Files {
-					cachedFiles = append(cachedFiles, drive.DriveFile{
-						ID:           cf.ID,
-						Name:         cf.Name,
-						Path:         cf.Path,
-						Size:         cf.Size,
-						FolderID:     cf.FolderID,
-						MimeType:     cf.MimeType,
-						CreatedTime:  cf.CreatedTime,
-						ModifiedTime: cf.ModifiedTime,
-					})
-				}
 				cachedAt := map[string]time.Time{cacheKey: cached.FetchedAt}
-				return filesFromCacheMsg{files: cachedFiles, cachedAt: cachedAt}
+				return filesFromCacheMsg{files: cachedToDriveFiles(cached.Files), cachedAt: cachedAt}
 			}
 		}
 
-		// Fetch from Google Drive
-		files, err := m.driveClient.ListFilesFromFolders(m.ctx, m.links)
-		if err != nil {
-			return errMsg{err}
+		// Fetch from Google Drive, streaming pages to the model as they arrive
+		pages := make(chan []drive.DriveFile, 16)
+		result := make(chan tea.Msg, 1)
+		go func() {
+			files, err := m.driveClient.ListFilesFromFoldersStream(m.ctx, m.links, m.driveClient.MaxDepth(), m.maxConcurrent, pages)
+			close(pages)
+			if err != nil && len(files) == 0 {
+				result <- errMsg{err}
+				return
+			}
+			// Only cache complete listings, so a retry lists the failed folders again
+			if err == nil {
+				m.saveToCache(cacheKey, files)
+			}
+			result <- filesLoadedMsg{files: files, previous: previous, warning: err, duplicates: m.driveClient.CollapsedDuplicates()}
+		}()
+
+		return waitForListing(pages, result)()
+	}
+}
+
+// loadOfflineFiles serves a listing from the cache alone: the combined entry
+// for these folders if there is one, otherwise each folder's own entry
+func (m Model) loadOfflineFiles(cacheKey string, folderIDs []string) tea.Msg {
+	if m.cacheManager == nil {
+		return errMsg{fmt.Errorf("offline mode needs the cache, but it could not be opened")}
+	}
+
+	entries := []string{cacheKey}
+	if m.cacheManager.GetFolder(cacheKey) == nil {
+		entries = folderIDs
+	}
+
+	var files []drive.DriveFile
+	cachedAt := make(map[string]time.Time)
+	for _, key := range entries {
+		cached := m.cacheManager.GetFolder(key)
+		if cached == nil {
+			return errMsg{fmt.Errorf("no cached listing for folder %s; run once online to cache it", key)}
 		}
+		files = append(files, cachedToDriveFiles(cached.Files)...)
+		cachedAt[key] = cached.FetchedAt
+	}
+	// Separate entries can overlap like the links they were listed from
+	files, _ = drive.DedupeFiles(files, false)
+	return filesFromCacheMsg{files: files, cachedAt: cachedAt}
+}
 
-		// Save to cache using combined key
-		if m.cacheManager != nil {
-			var cachedFiles []cache.CachedFile
-			for _, f := range files {
-				cachedFiles = append(cachedFiles, cache.CachedFile{
-					ID:           f.ID,
-					Name:         f.Name,
-					Path:         f.Path,
-					Size:         f.Size,
-					FolderID:     f.FolderID,
-					MimeType:     f.MimeType,
-					CreatedTime:  f.CreatedTime,
-					ModifiedTime: f.ModifiedTime,
-				})
-			}
-			m.cacheManager.SetFolder(cacheKey, "combined", cachedFiles)
+// waitForListing returns the next streamed page, or the final result once the
+// listing goroutine has closed the page channel
+func waitForListing(pages <-chan []drive.DriveFile, result <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		if page, ok := <-pages; ok {
+			return filesPageMsg{files: page, pages: pages, result: result}
 		}
+		return <-result
+	}
+}
+
+// cachedToDriveFiles converts cached entries back into drive files
+func cachedToDriveFiles(cached []cache.CachedFile) []drive.DriveFile {
+	files := make([]drive.DriveFile, 0, len(cached))
+	for _, cf := range cached {
+		files = append(files, drive.DriveFile{
+			ID:             cf.ID,
+			Name:           cf.Name,
+			Path:           cf.Path,
+			Size:           cf.Size,
+			MD5Checksum:    cf.MD5Checksum,
+			FolderID:       cf.FolderID,
+			RootFolderID:   cf.RootFolderID,
+			RootFolderName: cf.RootFolderName,
+			MimeType:       cf.MimeType,
+			Owner:          cf.Owner,
+			OwnerEmail:     cf.OwnerEmail,
+			CreatedTime:    cf.CreatedTime,
+			ModifiedTime:   cf.ModifiedTime,
+		})
+	}
+	return files
+}
 
-		return filesLoadedMsg{files}
+// saveToCache stores a fresh listing under the combined cache key
+func (m Model) saveToCache(cacheKey string, files []drive.DriveFile) {
+	if m.cacheManager != nil {
+		var cachedFiles []cache.CachedFile
+		for _, f := range files {
+			cachedFiles = append(cachedFiles, cache.CachedFile{
+				ID:             f.ID,
+				Name:           f.Name,
+				Path:           f.Path,
+				Size:           f.Size,
+				MD5Checksum:    f.MD5Checksum,
+				FolderID:       f.FolderID,
+				RootFolderID:   f.RootFolderID,
+				RootFolderName: f.RootFolderName,
+				MimeType:       f.MimeType,
+				Owner:          f.Owner,
+				OwnerEmail:     f.OwnerEmail,
+				CreatedTime:    f.CreatedTime,
+				ModifiedTime:   f.ModifiedTime,
+			})
+		}
+		m.cacheManager.SetFolder(cacheKey, "combined", cachedFiles)
 	}
 }
 
@@ -520,63 +1006,197 @@ func (m Model) refreshFiles() tea.Cmd {
 
 func (m Model) loadFiles() tea.Cmd {
 	return func() tea.Msg {
-		files, err := m.driveClient.ListFilesFromFolders(m.ctx, m.links)
-		if err != nil {
+		files, err := m.driveClient.ListFilesFromFoldersWithDepth(m.ctx, m.links, m.driveClient.MaxDepth(), m.maxConcurrent)
+		if err != nil && len(files) == 0 {
 			return errMsg{err}
 		}
-		return filesLoadedMsg{files}
+		return filesLoadedMsg{files: files, warning: err, duplicates: m.driveClient.CollapsedDuplicates()}
 	}
 }
 
+// sortFiles orders allFiles by the current sort field and direction. Ties are
+// broken by name and then ID, so equal rows keep the same order every time.
 func (m *Model) sortFiles() {
-	sort.Slice(m.allFiles, func(i, j int) bool {
-		var less bool
-		switch m.sortField {
-		case SortByName:
-			less = strings.ToLower(m.allFiles[i].Name) < strings.ToLower(m.allFiles[j].Name)
-		case SortBySize:
-			less = m.allFiles[i].Size < m.allFiles[j].Size
-		case SortByDate:
-			less = m.allFiles[i].ModifiedTime.Before(m.allFiles[j].ModifiedTime)
+	sort.SliceStable(m.allFiles, func(i, j int) bool {
+		a, b := m.allFiles[i], m.allFiles[j]
+		c := compareFiles(a, b, m.sortField)
+		if !m.sortAsc {
+			c = -c
 		}
-		if m.sortAsc {
-			return less
+		if c == 0 {
+			c = cmp.Or(
+				cmp.Compare(strings.ToLower(a.DisplayName()), strings.ToLower(b.DisplayName())),
+				cmp.Compare(a.ID, b.ID),
+			)
 		}
-		return !less
+		return c < 0
 	})
 }
 
-// getDisplayFiles returns the current file list (deduped or all)
+// compareFiles compares a and b by field alone, returning -1, 0, or +1
+func compareFiles(a, b drive.DriveFile, field SortField) int {
+	switch field {
+	case SortByName:
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case SortBySize:
+		return cmp.Compare(a.Size, b.Size)
+	case SortByDate:
+		return a.ModifiedTime.Compare(b.ModifiedTime)
+	case SortByFolder:
+		return cmp.Compare(strings.ToLower(rootFolderLabel(a)), strings.ToLower(rootFolderLabel(b)))
+	case SortByOwner:
+		return cmp.Compare(strings.ToLower(a.Owner), strings.ToLower(b.Owner))
+	}
+	return 0
+}
+
+// resort flips the sort direction for field and re-sorts, keeping the cursor
+// on the same file rather than the same row
+func (m *Model) resort(field SortField) {
+	var cursorID string
+	if rows, _ := m.listRows(m.getDisplayFiles()); m.fileCursor < len(rows) {
+		cursorID = rows[m.fileCursor].ID
+	}
+
+	m.sortField = field
+	m.sortAsc = !m.sortAsc
+	m.sortFiles()
+	if m.showDeduped {
+		m.dedupedFiles = dedupeFiles(m.allFiles)
+	}
+
+	rows, _ := m.listRows(m.getDisplayFiles())
+	for i, f := range rows {
+		if f.ID == cursorID {
+			m.fileCursor = i
+			break
+		}
+	}
+}
+
+// refreshDerivedLists recomputes the search and dedupe views of allFiles after it
+// changes, and keeps the cursor within the visible list
+func (m *Model) refreshDerivedLists() {
+	if m.view == ViewFiles {
+		m.filteredFiles = m.filterFiles(m.searchTerms)
+	}
+	if m.showDeduped {
+		m.dedupedFiles = dedupeFiles(m.allFiles)
+		m.dedupedFilteredFiles = dedupeFiles(m.filteredFiles)
+	}
+
+	var n int
+	if m.view == ViewFiles {
+		n = len(m.getDisplayFilteredFiles())
+	} else {
+		rows, _ := m.listRows(m.getDisplayFiles())
+		n = len(rows)
+	}
+	if m.fileCursor >= n {
+		m.fileCursor = max(n-1, 0)
+	}
+}
+
+// getDisplayFiles returns the current file list (deduped or all), without
+// downloaded files when they are hidden
 func (m Model) getDisplayFiles() []drive.DriveFile {
 	if m.showDeduped && len(m.dedupedFiles) > 0 {
-		return m.dedupedFiles
+		return m.hideDownloaded(m.listFilters(m.dedupedFiles))
 	}
-	return m.allFiles
+	return m.hideDownloaded(m.listFilters(m.allFiles))
 }
 
-// getDisplayFilteredFiles returns the current filtered file list (deduped or all)
+// getDisplayFilteredFiles returns the current filtered file list (deduped or
+// all), without downloaded files when they are hidden
 func (m Model) getDisplayFilteredFiles() []drive.DriveFile {
 	if m.showDeduped && len(m.dedupedFilteredFiles) > 0 {
-		return m.dedupedFilteredFiles
+		return m.hideDownloaded(m.listFilters(m.dedupedFilteredFiles))
+	}
+	return m.hideDownloaded(m.listFilters(m.filteredFiles))
+}
+
+// hideDownloaded drops files that already exist locally when hideExisting is on
+func (m Model) hideDownloaded(files []drive.DriveFile) []drive.DriveFile {
+	if !m.hideExisting {
+		return files
+	}
+	missing := make([]drive.DriveFile, 0, len(files))
+	for _, f := range files {
+		if m.fileExistsCache[f.ID] != drive.LocalComplete {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// hiddenIndicator notes how many downloaded files are hidden from a list of total
+func (m Model) hiddenIndicator(total, shown int) string {
+	if !m.hideExisting {
+		return ""
+	}
+	return fmt.Sprintf(" [%d downloaded hidden]", total-shown)
+}
+
+// toggleHideExisting shows or hides files that already exist locally
+func (m *Model) toggleHideExisting() {
+	m.hideExisting = !m.hideExisting
+	m.fileCursor = 0
+	if m.hideExisting {
+		m.status = "Hiding files already downloaded"
+	} else {
+		m.status = "Showing all files"
+	}
+}
+
+// togglePaths switches the flat list between names with their Drive path and
+// bare names
+func (m *Model) togglePaths() {
+	m.hidePaths = !m.hidePaths
+	if m.hidePaths {
+		m.status = "Showing file names only (i shows the path)"
+	} else {
+		m.status = "Showing file paths"
 	}
-	return m.filteredFiles
 }
 
 func (m Model) updateFileList(msg tea.Msg) (tea.Model, tea.Cmd) {
 	displayFiles := m.getDisplayFiles()
+	rows, _ := m.listRows(displayFiles)
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// Handle popup close first
+		// Handle popup keys first
 		if m.showInfoPopup {
+			var row drive.DriveFile
+			if m.fileCursor < len(rows) {
+				row = rows[m.fileCursor]
+			}
+			return m.updateInfoPopup(msg, row)
+		}
+		if m.showTypesPopup {
+			switch msg.String() {
+			case "b", "esc":
+				m.showTypesPopup = false
+			}
+			return m, nil
+		}
+		if m.savedSelection != nil {
 			switch msg.String() {
-			case "i", "esc":
-				m.showInfoPopup = false
+			case "y":
+				m.answerRestorePrompt(true)
+				return m, nil
+			case "n":
+				m.answerRestorePrompt(false)
 				return m, nil
 			}
-			return m, nil // Ignore other keys when popup is open
 		}
 
+		if m.readCountDigit(msg) {
+			return m, nil
+		}
+		count := m.countPrefix
+		m.countPrefix = 0
+
 		switch msg.String() {
 		case "up", "k":
 			m.lastKeyG = false
@@ -585,7 +1205,7 @@ func (m Model) updateFileList(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "down", "j":
 			m.lastKeyG = false
-			if m.fileCursor < len(displayFiles)-1 {
+			if m.fileCursor < len(rows)-1 {
 				m.fileCursor++
 			}
 		case "g":
@@ -598,51 +1218,45 @@ func (m Model) updateFileList(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "G":
 			m.lastKeyG = false
-			if len(displayFiles) > 0 {
-				m.fileCursor = len(displayFiles) - 1
+			if len(rows) > 0 {
+				m.fileCursor = len(rows) - 1
 			}
+		case "]", "[":
+			m.lastKeyG = false
+			m.jumpToSelected(displayFiles, rows, msg.String() == "[")
 		case "n":
 			m.lastKeyG = false
-			m.sortField = SortByName
-			m.sortAsc = !m.sortAsc
-			m.sortFiles()
-			if m.showDeduped {
-				m.dedupedFiles = dedupeFiles(m.allFiles)
-			}
+			m.resort(SortByName)
 		case "s":
 			m.lastKeyG = false
-			m.sortField = SortBySize
-			m.sortAsc = !m.sortAsc
-			m.sortFiles()
-			if m.showDeduped {
-				m.dedupedFiles = dedupeFiles(m.allFiles)
-			}
+			m.resort(SortBySize)
 		case "d":
 			m.lastKeyG = false
-			m.sortField = SortByDate
-			m.sortAsc = !m.sortAsc
-			m.sortFiles()
-			if m.showDeduped {
-				m.dedupedFiles = dedupeFiles(m.allFiles)
-			}
+			m.resort(SortByDate)
+		case "f":
+			m.lastKeyG = false
+			m.resort(SortByFolder)
+		case "w":
+			m.lastKeyG = false
+			m.resort(SortByOwner)
 		case " ":
 			m.lastKeyG = false
-			// Toggle selection for current file
-			if m.fileCursor < len(displayFiles) {
-				f := displayFiles[m.fileCursor]
-				m.selectedFiles[f.ID] = !m.selectedFiles[f.ID]
+			// Toggle selection for current file, or everything in a folder
+			if m.fileCursor < len(rows) {
+				m.toggleRow(displayFiles, rows[m.fileCursor])
 			}
 		case "a":
 			m.lastKeyG = false
 			// Toggle all files in current view
 			m.selectAll = !m.selectAll
-			for _, f := range displayFiles {
+			for _, f := range m.filesInView(displayFiles) {
 				m.selectedFiles[f.ID] = m.selectAll
 			}
 		case "i":
 			m.lastKeyG = false
-			// Toggle info popup
-			m.showInfoPopup = !m.showInfoPopup
+			if m.fileCursor < len(rows) {
+				return m, m.openInfo(rows[m.fileCursor])
+			}
 		case "u":
 			m.lastKeyG = false
 			// Toggle dedupe mode - show only smallest version of duplicates
@@ -651,23 +1265,82 @@ func (m Model) updateFileList(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.dedupedFiles = dedupeFiles(m.allFiles)
 			}
 			m.fileCursor = 0
+		case "h":
+			m.lastKeyG = false
+			m.toggleHideExisting()
+		case "p":
+			m.lastKeyG = false
+			m.togglePaths()
+		case "b":
+			m.lastKeyG = false
+			m.showTypesPopup = true
+		case "m":
+			m.lastKeyG = false
+			return m.editSince()
+		case "S":
+			m.lastKeyG = false
+			m.saveSelection()
 		case "r":
 			m.lastKeyG = false
+			if m.offline {
+				m.err = fmt.Errorf("refresh is disabled in offline mode")
+				return m, nil
+			}
 			// Refresh files from Google Drive (bypass cache)
 			return m, m.refreshFiles()
+		case "y":
+			m.lastKeyG = false
+			m.copySelectedLinks(m.filesInView(displayFiles))
+		case "Y":
+			m.lastKeyG = false
+			m.copySelectionSummary(m.filesInView(displayFiles))
+		case "t":
+			m.lastKeyG = false
+			m.selectTopN(m.filesInView(displayFiles), count)
+		case "o":
+			m.lastKeyG = false
+			return m.editDestDir()
+		case "O":
+			m.lastKeyG = false
+			m.openFolder(rows)
+		case "x":
+			m.lastKeyG = false
+			m.forceDownload = !m.forceDownload
+		case "F":
+			m.lastKeyG = false
+			m.toggleBrowse()
+		case "l", "right":
+			m.lastKeyG = false
+			if m.fileCursor < len(rows) && isFolderRow(rows[m.fileCursor]) {
+				m.openFolderRow(rows[m.fileCursor])
+			}
+		case "backspace", "left":
+			m.lastKeyG = false
+			if m.browsing {
+				m.browseUp()
+			}
 		case "enter":
 			m.lastKeyG = false
+			// Open a folder of the browse view
+			if m.fileCursor < len(rows) && isFolderRow(rows[m.fileCursor]) {
+				m.openFolderRow(rows[m.fileCursor])
+				return m, nil
+			}
 			// Download selected files
 			m.filteredFiles = m.allFiles
 			return m.startDownload()
 		case "/":
 			m.lastKeyG = false
+			m.listCursor = m.fileCursor
 			m.view = ViewSearch
 			m.searchInput.Focus()
 			return m, textinput.Blink
 		default:
 			m.lastKeyG = false
 		}
+
+	case tea.MouseMsg:
+		m.handleListMouse(msg, rows)
 	}
 
 	return m, nil
@@ -679,6 +1352,9 @@ func (m Model) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "enter":
 			return m.submitSearch()
+		case "tab":
+			m.searchNameOnly = !m.searchNameOnly
+			return m, nil
 		}
 	}
 
@@ -687,6 +1363,50 @@ func (m Model) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// editDestDir opens the destination directory input, pre-filled with the current directory
+func (m Model) editDestDir() (tea.Model, tea.Cmd) {
+	m.destReturnView = m.view
+	m.view = ViewDestDir
+	m.destInput.SetValue(m.destDir)
+	m.destInput.CursorEnd()
+	m.destInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) updateDestDir(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "enter" {
+		dir := strings.TrimSpace(m.destInput.Value())
+		if dir == "" {
+			m.err = fmt.Errorf("destination directory cannot be empty")
+			return m, nil
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			m.err = fmt.Errorf("unable to create output directory: %w", err)
+			return m, nil
+		}
+
+		m.destDir = dir
+		m.err = nil
+		m.updateFileExistsCache()
+		m.destInput.Blur()
+		m.view = m.destReturnView
+		m.status = fmt.Sprintf("Downloading to %s", dir)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.destInput, cmd = m.destInput.Update(msg)
+	return m, cmd
+}
+
+// filterFiles applies search terms to allFiles using the current match scope
+func (m Model) filterFiles(terms []string) []drive.DriveFile {
+	if m.searchNameOnly {
+		return drive.FilterFilesByName(m.allFiles, terms)
+	}
+	return drive.FilterFiles(m.allFiles, terms)
+}
+
 func (m Model) submitSearch() (tea.Model, tea.Cmd) {
 	terms := strings.Split(m.searchInput.Value(), ",")
 	var cleanTerms []string
@@ -698,16 +1418,15 @@ func (m Model) submitSearch() (tea.Model, tea.Cmd) {
 	}
 
 	m.searchTerms = cleanTerms
-	m.filteredFiles = drive.FilterFiles(m.allFiles, cleanTerms)
+	m.filteredFiles = m.filterFiles(cleanTerms)
 
 	if len(m.filteredFiles) == 0 {
 		m.err = fmt.Errorf("no files match the search terms")
 		return m, nil
 	}
 
-	// Select all by default
+	// Select all matches by default, on top of anything picked in the full list
 	m.selectAll = true
-	m.selectedFiles = make(map[string]bool)
 	for _, f := range m.filteredFiles {
 		m.selectedFiles[f.ID] = true
 	}
@@ -723,15 +1442,20 @@ func (m Model) updateFiles(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// Handle popup close first
+		// Handle popup keys first
 		if m.showInfoPopup {
-			switch msg.String() {
-			case "i", "esc":
-				m.showInfoPopup = false
-				return m, nil
+			var row drive.DriveFile
+			if m.fileCursor < len(displayFiles) {
+				row = displayFiles[m.fileCursor]
 			}
-			return m, nil // Ignore other keys when popup is open
+			return m.updateInfoPopup(msg, row)
+		}
+
+		if m.readCountDigit(msg) {
+			return m, nil
 		}
+		count := m.countPrefix
+		m.countPrefix = 0
 
 		switch msg.String() {
 		case "up", "k":
@@ -757,6 +1481,9 @@ func (m Model) updateFiles(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if len(displayFiles) > 0 {
 				m.fileCursor = len(displayFiles) - 1
 			}
+		case "]", "[":
+			m.lastKeyG = false
+			m.jumpToSelected(displayFiles, displayFiles, msg.String() == "[")
 		case " ":
 			m.lastKeyG = false
 			if m.fileCursor < len(displayFiles) {
@@ -771,8 +1498,9 @@ func (m Model) updateFiles(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "i":
 			m.lastKeyG = false
-			// Toggle info popup
-			m.showInfoPopup = !m.showInfoPopup
+			if m.fileCursor < len(displayFiles) {
+				return m, m.openInfo(displayFiles[m.fileCursor])
+			}
 		case "u":
 			m.lastKeyG = false
 			// Toggle dedupe mode - show only smallest version of duplicates
@@ -781,6 +1509,36 @@ func (m Model) updateFiles(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.dedupedFilteredFiles = dedupeFiles(m.filteredFiles)
 			}
 			m.fileCursor = 0
+		case "h":
+			m.lastKeyG = false
+			m.toggleHideExisting()
+		case "p":
+			m.lastKeyG = false
+			m.togglePaths()
+		case "m":
+			m.lastKeyG = false
+			return m.editSince()
+		case "S":
+			m.lastKeyG = false
+			m.saveSelection()
+		case "y":
+			m.lastKeyG = false
+			m.copySelectedLinks(displayFiles)
+		case "Y":
+			m.lastKeyG = false
+			m.copySelectionSummary(displayFiles)
+		case "t":
+			m.lastKeyG = false
+			m.selectTopN(displayFiles, count)
+		case "o":
+			m.lastKeyG = false
+			return m.editDestDir()
+		case "O":
+			m.lastKeyG = false
+			m.openFolder(displayFiles)
+		case "x":
+			m.lastKeyG = false
+			m.forceDownload = !m.forceDownload
 		case "enter":
 			m.lastKeyG = false
 			return m.startDownload()
@@ -793,13 +1551,162 @@ func (m Model) updateFiles(msg tea.Msg) (tea.Model, tea.Cmd) {
 		default:
 			m.lastKeyG = false
 		}
+
+	case tea.MouseMsg:
+		m.handleListMouse(msg, displayFiles)
 	}
 
 	return m, nil
 }
 
-func (m Model) startDownload() (tea.Model, tea.Cmd) {
-	// Use the appropriate file list based on current view and dedupe mode
+// checkboxColumnStart and checkboxColumnEnd bound the "[ ]" column in a file row.
+const (
+	checkboxColumnStart = 2
+	checkboxColumnEnd   = 5
+)
+
+// handleListMouse handles clicks and wheel scrolling in the file list views.
+// Clicking a row moves the cursor to it, clicking the checkbox toggles selection,
+// and the wheel moves the cursor (which scrolls the visible window with it).
+func (m *Model) handleListMouse(msg tea.MouseMsg, files []drive.DriveFile) {
+	if m.showInfoPopup || len(files) == 0 {
+		return
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if m.fileCursor > 0 {
+			m.fileCursor--
+		}
+	case tea.MouseButtonWheelDown:
+		if m.fileCursor < len(files)-1 {
+			m.fileCursor++
+		}
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return
+		}
+		visibleStart, visibleEnd := m.visibleRange(len(files))
+		top := m.listTopLines() + m.warningLines()
+		idx := visibleStart + msg.Y - top
+		if msg.Y < top || idx >= visibleEnd {
+			return
+		}
+		m.lastKeyG = false
+		m.fileCursor = idx
+		if msg.X >= checkboxColumnStart && msg.X < checkboxColumnEnd {
+			m.toggleRow(m.getDisplayFiles(), files[idx])
+		}
+	}
+}
+
+// copySelectedLinks copies the web links of the selected files to the clipboard
+func (m *Model) copySelectedLinks(files []drive.DriveFile) {
+	var links []string
+	for _, f := range files {
+		if m.selectedFiles[f.ID] {
+			links = append(links, f.WebLink())
+		}
+	}
+	if len(links) == 0 {
+		m.err = fmt.Errorf("no files selected")
+		return
+	}
+
+	if err := clipboard.WriteAll(strings.Join(links, "\n")); err != nil {
+		m.err = fmt.Errorf("unable to copy to clipboard: %w", err)
+		return
+	}
+	m.err = nil
+	m.status = fmt.Sprintf("Copied %d links to clipboard", len(links))
+}
+
+// defaultTopN is how many files "t" selects when no count is typed first
+const defaultTopN = 10
+
+// readCountDigit accumulates a digit key into countPrefix and reports whether
+// the key was consumed. A leading 0 is not a count.
+func (m *Model) readCountDigit(msg tea.KeyMsg) bool {
+	k := msg.String()
+	if len(k) != 1 || k[0] < '0' || k[0] > '9' || (k == "0" && m.countPrefix == 0) {
+		return false
+	}
+	m.lastKeyG = false
+	m.countPrefix = min(m.countPrefix*10+int(k[0]-'0'), 1_000_000)
+	m.status = fmt.Sprintf("Count: %d", m.countPrefix)
+	return true
+}
+
+// selectTopN replaces the selection with the first n files of the visible list,
+// i.e. the top n by the active sort (newest N when sorted by date, and so on)
+func (m *Model) selectTopN(files []drive.DriveFile, n int) {
+	if n <= 0 {
+		n = defaultTopN
+	}
+	n = min(n, len(files))
+
+	m.selectAll = false
+	for i, f := range files {
+		m.selectedFiles[f.ID] = i < n
+	}
+	m.status = fmt.Sprintf("Selected top %d files", n)
+}
+
+// showNoFiles explains an empty listing. Interactively it returns to the links
+// view so they can be edited; in auto-download mode it ends the run.
+func (m Model) showNoFiles() (tea.Model, tea.Cmd) {
+	m.err = fmt.Errorf("no downloadable files found in the provided folders")
+	if m.autoDownload {
+		m.view = ViewDone
+		m.reportError = m.writeReport()
+		return m, nil
+	}
+	m.view = ViewLinks
+	m.linksInput.Focus()
+	return m, textarea.Blink
+}
+
+// NoFiles reports whether the last listing found no files in any folder.
+func (m Model) NoFiles() bool {
+	return m.noFiles
+}
+
+// CapError returns why an automatic download was refused by -max-files or
+// -max-bytes, or nil if it wasn't.
+func (m Model) CapError() error {
+	return m.capErr
+}
+
+// skipIndicator describes in the list header whether existing files will be skipped
+func (m Model) skipIndicator() string {
+	switch {
+	case m.forceDownload:
+		return " [re-download existing]"
+	case m.existPolicy == drive.ExistSkip || m.existPolicy == drive.ExistNewer:
+		return " [skip existing]"
+	}
+	return ""
+}
+
+// checkCaps returns an error if files exceed the -max-files or -max-bytes cap
+func (m Model) checkCaps(files []drive.DriveFile) error {
+	if m.maxFiles > 0 && len(files) > m.maxFiles {
+		return fmt.Errorf("selection of %d files exceeds -max-files %d; deselect some files or raise the cap", len(files), m.maxFiles)
+	}
+	if m.maxBytes > 0 {
+		var total int64
+		for _, f := range files {
+			total += f.Size
+		}
+		if total > m.maxBytes {
+			return fmt.Errorf("selection of %s exceeds -max-bytes %s; deselect some files or raise the cap", formatSize(total), formatSize(m.maxBytes))
+		}
+	}
+	return nil
+}
+
+func (m Model) startDownload() (tea.Model, tea.Cmd) {
+	// Use the appropriate file list based on current view and dedupe mode
 	var sourceFiles []drive.DriveFile
 	if m.view == ViewFileList {
 		sourceFiles = m.getDisplayFiles()
@@ -818,102 +1725,392 @@ func (m Model) startDownload() (tea.Model, tea.Cmd) {
 		m.err = fmt.Errorf("no files selected")
 		return m, nil
 	}
+	return m.downloadBatch(toDownload)
+}
+
+// downloadBatch starts downloading toDownload, the selection or a single
+// revision picked in the info popup
+func (m Model) downloadBatch(toDownload []drive.DriveFile) (tea.Model, tea.Cmd) {
+	if m.offline {
+		m.err = fmt.Errorf("downloads are disabled in offline mode")
+		return m, nil
+	}
+
+	// Files an interrupted run of this batch finished aren't downloaded again
+	m.batchID = m.batchKey(toDownload)
+	toDownload, resumed := m.resumeBatch(toDownload)
+
+	if err := m.checkCaps(toDownload); err != nil {
+		m.err = err
+		if m.autoDownload {
+			// Nobody is there to adjust the selection, so stop here
+			m.capExceeded = true
+			m.capErr = err
+			m.view = ViewDone
+			m.reportError = m.writeReport()
+		}
+		return m, nil
+	}
 
-	m.totalToDownload = len(toDownload)
-	m.completedCount = 0
+	// Likewise take the status address first, in case something else holds it
+	var statusListener net.Listener
+	if m.statusAddr != "" {
+		ln, err := net.Listen("tcp", m.statusAddr)
+		if err != nil {
+			m.err = fmt.Errorf("unable to serve status: %w", err)
+			return m, nil
+		}
+		statusListener = ln
+	}
+
+	// Open the archive up front so a bad output directory fails before any download
+	var arch *archiver
+	m.archivePath = ""
+	m.archiveErrors = nil
+	m.manifestError = nil
+	if m.archiveFormat != ArchiveNone {
+		path := filepath.Join(m.outputDir(), archiveName(m.archiveFormat, time.Now()))
+		if err := os.MkdirAll(m.outputDir(), 0o755); err != nil {
+			m.err = fmt.Errorf("unable to create output directory: %w", err)
+			closeListener(statusListener)
+			return m, nil
+		}
+		a, err := createArchive(path, m.archiveFormat)
+		if err != nil {
+			m.err = err
+			closeListener(statusListener)
+			return m, nil
+		}
+		arch = a
+		m.archivePath = path
+	}
+
+	// The exists indicator keeps using m.existPolicy; only the download changes
+	if m.forceDownload {
+		m.driveClient.SetExistPolicy(drive.ExistOverwrite)
+	} else {
+		m.driveClient.SetExistPolicy(m.existPolicy)
+	}
+	m.prompts = nil
+	m.driveClient.SetOverwriteConfirm(nil)
+	if m.confirmOverwrite {
+		m.prompts = &overwritePrompts{}
+		m.driveClient.SetOverwriteConfirm(m.prompts.confirm)
+	}
+
+	m.progressMu.Lock()
+	m.batch = drive.NewBatchTracker(toDownload)
+	m.progressMu.Unlock()
+	m.batchStarted = time.Now()
 	m.downloadingFiles = toDownload // Store the files being downloaded
+	m.queue = newDownloadQueue(toDownload)
+	m.active = newActiveDownloads()
+	m.pool = newAdaptivePool(m.ctx, m.maxConcurrent)
+	m.driveClient.SetQuotaErrorHook(m.pool.quotaError)
+	m.queueCursor = 0
+	m.queueFilter.SetValue("")
+	m.failedFocus = ""
 	m.view = ViewDownloading
 	m.downloading = true
+	m.openBatchState()
+
+	if statusListener != nil {
+		m.statusServer = m.serveStatus(statusListener)
+		m.status = "Serving progress at http://" + statusListener.Addr().String() + "/status"
+	}
+	if resumed > 0 {
+		note := resumeStatus(resumed)
+		if statusListener != nil {
+			note += "; " + m.status
+		}
+		m.status = note
+	}
 
 	return m, tea.Batch(
-		m.downloadFiles(toDownload),
+		m.downloadFiles(toDownload, arch),
 		tea.Tick(100*time.Millisecond, func(_ time.Time) tea.Msg { return tickMsg{} }),
 	)
 }
 
-func (m *Model) downloadFiles(files []drive.DriveFile) tea.Cmd {
+// downloadTitle returns the window title for a running download, "N/M (P%)"
+// counting finished files
+func (m Model) downloadTitle() string {
+	m.progressMu.Lock()
+	p := m.batch.Progress()
+	m.progressMu.Unlock()
+
+	pct := 0
+	if p.FilesTotal > 0 {
+		pct = p.FilesDone * 100 / p.FilesTotal
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", p.FilesDone, p.FilesTotal, pct)
+}
+
+// quit clears any window title set during downloads, then exits the program
+func (m Model) quit() tea.Cmd {
+	if m.windowTitle == "" {
+		return tea.Quit
+	}
+	return tea.Sequence(tea.SetWindowTitle(""), tea.Quit)
+}
+
+// shutdownGracePeriod is how long cancelled workers get to stop and remove
+// their partial files before the program quits anyway.
+const shutdownGracePeriod = 5 * time.Second
+
+// beginShutdown cancels all in-flight downloads and waits (up to
+// shutdownGracePeriod) for the workers to finish before showing the summary.
+func (m Model) beginShutdown() (tea.Model, tea.Cmd) {
+	m.cancelling = true
+	m.cancel()
+	return m, tea.Tick(shutdownGracePeriod, func(_ time.Time) tea.Msg { return shutdownTimeoutMsg{} })
+}
+
+func (m *Model) downloadFiles(files []drive.DriveFile, arch *archiver) tea.Cmd {
 	return func() tea.Msg {
-		destDir := m.destDir
-		if destDir == "" {
-			destDir = "./output"
+		destDir := m.outputDir()
+
+		// Finished files are funnelled to a single archiver goroutine, since
+		// archive writers can't be shared between workers
+		var archiveItems chan archiveItem
+		var archiveErrors []string
+		archiverDone := make(chan struct{})
+		if arch != nil {
+			archiveItems = make(chan archiveItem, m.maxConcurrent)
+			go func() {
+				archiveErrors = runArchiver(arch, archiveItems)
+				close(archiverDone)
+			}()
+		} else {
+			close(archiverDone)
 		}
 
+		queue, pool := m.queue, m.pool
 		var wg sync.WaitGroup
-		sem := make(chan struct{}, m.maxConcurrent)
 		var errorsMu sync.Mutex
 		var errors []string
-
-		for _, file := range files {
+		var manifest []drive.ManifestEntry
+
+		// All workers funnel progress through one channel so that only the
+		// updater goroutine takes progressMu, once per batch rather than per chunk
+		updates := make(chan progressUpdate, progressBufferSize)
+		updaterDone := make(chan struct{})
+		go func() {
+			m.applyProgressUpdates(updates)
+			close(updaterDone)
+		}()
+
+		// Workers take files from the front of the queue, so pending files can
+		// still be reordered or removed. There is one per slot the pool can
+		// grow to; it decides how many are downloading at a time.
+		for range min(pool.ceiling, len(files)) {
 			wg.Add(1)
-			go func(f drive.DriveFile) {
+			go func() {
 				defer wg.Done()
-				sem <- struct{}{}
-				defer func() { <-sem }()
-
-				// Check if context was cancelled while waiting for semaphore
-				if m.ctx.Err() != nil {
-					return
-				}
-
-				m.progressMu.Lock()
-				m.fileProgress[f.ID] = drive.DownloadProgress{
-					FileID:      f.ID,
-					FileName:    f.DisplayName(),
-					TotalBytes:  f.Size,
-					BytesLoaded: 0,
-				}
-				m.progressMu.Unlock()
-
-				// Create a progress channel for this file
-				progressChan := make(chan drive.DownloadProgress, 100)
-
-				// Goroutine to update progress
-				done := make(chan struct{})
-				go func() {
-					for prog := range progressChan {
-						m.progressMu.Lock()
-						m.fileProgress[f.ID] = prog
-						m.progressMu.Unlock()
+				for {
+					// Wait for a slot; this also stops taking new files once
+					// the run is cancelled
+					if !pool.acquire() {
+						return
+					}
+					f, ok := queue.pop()
+					if !ok {
+						pool.release()
+						return
+					}
+					saved, err := m.downloadOne(f, destDir, updates)
+					pool.release()
+					if err != nil {
+						errorsMu.Lock()
+						errors = append(errors, fmt.Sprintf("%s: %v", f.DisplayName(), err))
+						errorsMu.Unlock()
+						continue
+					}
+					if m.manifest && saved.LocalPath != "" && !saved.Skipped {
+						entry := drive.NewManifestEntry(destDir, f, saved.LocalPath, time.Now())
+						errorsMu.Lock()
+						manifest = append(manifest, entry)
+						errorsMu.Unlock()
+					}
+					if archiveItems != nil && saved.LocalPath != "" {
+						name, err := archiveEntryName(destDir, saved.LocalPath)
+						if err != nil {
+							name = f.DisplayName()
+						}
+						// Copies that were already there before this run are left alone
+						archiveItems <- archiveItem{name: name, localPath: saved.LocalPath, remove: m.archiveOnly && !saved.Skipped}
 					}
-					close(done)
-				}()
-
-				err := m.driveClient.DownloadFile(m.ctx, f, destDir, progressChan)
-				close(progressChan)
-				<-done // Wait for progress updates to finish
-
-				m.progressMu.Lock()
-				m.fileProgress[f.ID] = drive.DownloadProgress{
-					FileID:      f.ID,
-					FileName:    f.DisplayName(),
-					TotalBytes:  f.Size,
-					BytesLoaded: f.Size,
-					Done:        true,
-					Error:       err,
 				}
-				m.completedCount++
-				m.progressMu.Unlock()
+			}()
+		}
+
+		wg.Wait()
+		if archiveItems != nil {
+			close(archiveItems)
+		}
+		<-archiverDone
+		close(updates)
+		<-updaterDone
+		manifestErr := drive.UpdateManifest(destDir, manifest)
+		return downloadCompleteMsg{errors: errors, archiveErrors: archiveErrors, manifestError: manifestErr}
+	}
+}
+
+// downloadOne downloads f, reporting its progress on updates. It returns the
+// final progress from DownloadFile, which is zero unless the file was saved or
+// skipped, and the download error, or nil on success, skip, or cancellation of
+// the whole run.
+func (m *Model) downloadOne(f drive.DriveFile, destDir string, updates chan<- progressUpdate) (drive.DownloadProgress, error) {
+	updates <- progressUpdate{prog: drive.DownloadProgress{
+		FileID:      f.ID,
+		FileName:    f.DisplayName(),
+		TotalBytes:  f.Size,
+		BytesLoaded: 0,
+	}}
+
+	started := time.Now()
+
+	// Forward this file's progress into the shared channel
+	progressChan := make(chan drive.DownloadProgress, 100)
+	done := make(chan struct{})
+	var saved drive.DownloadProgress
+	go func() {
+		for prog := range progressChan {
+			if prog.Done {
+				saved = prog
+			}
+			updates <- progressUpdate{prog: prog}
+		}
+		close(done)
+	}()
+
+	// Derive a per-file context so a hung transfer frees its worker and the
+	// file can be cancelled on its own. It is a child of m.ctx, so
+	// cancelling the run still cancels it.
+	fileCtx, fileCancel := context.WithCancelCause(m.ctx)
+	m.active.add(f.ID, fileCancel)
+	dlCtx, dlCancel := fileCtx, context.CancelFunc(func() {})
+	if m.downloadTimeout > 0 {
+		dlCtx, dlCancel = context.WithTimeout(fileCtx, m.downloadTimeout)
+	}
+	err := m.driveClient.DownloadFile(dlCtx, f, destDir, progressChan)
+	if err != nil && dlCtx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %v: %w", m.downloadTimeout, err)
+	}
+	dlCancel()
+	m.active.remove(f.ID)
+	cancelledByUser := err != nil && context.Cause(fileCtx) == errCancelledByUser
+	fileCancel(nil)
+	close(progressChan)
+	<-done // Wait for progress updates to finish
+
+	final := drive.DownloadProgress{
+		FileID:      f.ID,
+		FileName:    f.DisplayName(),
+		TotalBytes:  f.Size,
+		BytesLoaded: f.Size,
+		Done:        true,
+		Error:       err,
+	}
+	if err != nil && (m.ctx.Err() != nil || cancelledByUser) {
+		// The file or the whole run was cancelled or timed out, this isn't a real failure
+		final.BytesLoaded = 0
+		final.Error = nil
+		final.Cancelled = true
+		err = nil
+	}
+
+	// Sent after all of this file's progress, so it is applied last
+	updates <- progressUpdate{prog: final, final: true, file: f, elapsed: time.Since(started)}
+
+	if err != nil || final.Cancelled {
+		return drive.DownloadProgress{}, err
+	}
+	return saved, nil
+}
 
-				if err != nil {
-					errorsMu.Lock()
-					errors = append(errors, fmt.Sprintf("%s: %v", f.DisplayName(), err))
-					errorsMu.Unlock()
+// progressBufferSize is the capacity of the shared progress channel, and the
+// most updates applied under a single lock
+const progressBufferSize = 256
+
+// progressUpdate is a progress report from a download worker. The final update
+// for a file carries the file and elapsed time for the download log.
+type progressUpdate struct {
+	prog    drive.DownloadProgress
+	final   bool
+	file    drive.DriveFile
+	elapsed time.Duration
+}
+
+// applyProgressUpdates drains updates until it is closed, applying whatever is
+// queued in one batch per lock. A file's final update keeps the Skipped flag
+// reported by DownloadFile, and counts the file as completed.
+func (m *Model) applyProgressUpdates(updates <-chan progressUpdate) {
+	batch := make([]progressUpdate, 0, progressBufferSize)
+	for u := range updates {
+		batch = append(batch[:0], u)
+	drain:
+		for len(batch) < progressBufferSize {
+			select {
+			case u, ok := <-updates:
+				if !ok {
+					break drain
 				}
-			}(file)
+				batch = append(batch, u)
+			default:
+				break drain
+			}
 		}
 
-		wg.Wait()
-		return downloadCompleteMsg{errors: errors}
+		m.progressMu.Lock()
+		for i, u := range batch {
+			if u.final {
+				u.prog.Skipped = u.prog.Error == nil && !u.prog.Cancelled && m.fileProgress[u.prog.FileID].Skipped
+				batch[i] = u
+			}
+			if u.prog.Retry > 0 {
+				// A retry doesn't say how far the download got
+				u.prog.BytesLoaded = m.fileProgress[u.prog.FileID].BytesLoaded
+			}
+			m.fileProgress[u.prog.FileID] = u.prog
+
+			// Only the worker's final update settles a file; DownloadFile's
+			// own last report comes before any error is known
+			prog := u.prog
+			prog.Done = u.final
+			m.batch.Update(prog)
+		}
+		m.progressMu.Unlock()
+
+		// Log outside the lock so file I/O doesn't hold up the UI
+		for _, u := range batch {
+			if u.final {
+				m.downloadLog.Record(u.file, u.prog, u.elapsed)
+			}
+			if u.final && u.prog.Error == nil && !u.prog.Cancelled {
+				m.completeFile(u.file)
+			}
+		}
 	}
 }
 
+// appTitle heads every view but the compact layout
+const appTitle = "Google Drive Downloader"
+
 // View implements the Bubble Tea Model interface. It renders the current
 // view state to a string for display in the terminal.
 func (m Model) View() string {
+	if m.tooSmall() {
+		return m.viewTooSmall()
+	}
+
 	var s strings.Builder
 
-	s.WriteString(TitleStyle.Render("Google Drive Downloader"))
-	s.WriteString("\n")
+	// The compact layout leaves the title out to make room for files
+	if !m.compact() {
+		s.WriteString(TitleStyle.Render(appTitle))
+		s.WriteString("\n")
+	}
 
 	switch m.view {
 	case ViewLinks:
@@ -928,6 +2125,13 @@ func (m Model) View() string {
 		s.WriteString(m.viewDownloading())
 	case ViewDone:
 		s.WriteString(m.viewDone())
+	case ViewDestDir:
+		s.WriteString(m.viewDestDir())
+	}
+
+	if m.status != "" {
+		s.WriteString("\n")
+		s.WriteString(SuccessStyle.Render(m.status))
 	}
 
 	if m.err != nil {
@@ -945,7 +2149,7 @@ func (m Model) viewLinks() string {
 	s.WriteString("\n")
 	s.WriteString(m.linksInput.View())
 	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render("Ctrl+S to submit | Ctrl+C to quit"))
+	s.WriteString(HelpStyle.Render("Ctrl+S to submit | root lists My Drive | # starts a comment line | Ctrl+C to quit"))
 
 	if m.driveClient == nil {
 		s.WriteString("\n")
@@ -962,10 +2166,11 @@ func (m Model) viewFileList() string {
 	var s strings.Builder
 
 	// Use deduped files if mode is enabled
-	displayFiles := m.allFiles
+	baseFiles := m.allFiles
 	if m.showDeduped && len(m.dedupedFiles) > 0 {
-		displayFiles = m.dedupedFiles
+		baseFiles = m.dedupedFiles
 	}
+	displayFiles := m.getDisplayFiles()
 
 	// Calculate total size and selected count
 	var totalSize, selectedSize int64
@@ -981,12 +2186,19 @@ func (m Model) viewFileList() string {
 	// Show dedupe indicator if active
 	dedupeIndicator := ""
 	if m.showDeduped {
-		dedupeIndicator = fmt.Sprintf(" [DEDUPED: %d → %d]", len(m.allFiles), len(displayFiles))
+		dedupeIndicator = fmt.Sprintf(" [DEDUPED: %d → %d]", len(m.allFiles), len(baseFiles))
 	}
+	dedupeIndicator += m.sinceIndicator() + m.globIndicator()
+	dedupeIndicator += m.hiddenIndicator(len(m.listFilters(baseFiles)), len(displayFiles))
+	dedupeIndicator += m.browseIndicator()
 
 	// Show cache indicator
 	cacheIndicator := ""
-	if m.fromCache {
+	if m.listing {
+		cacheIndicator = " [loading...]"
+	} else if m.offline {
+		cacheIndicator = " [offline]"
+	} else if m.fromCache {
 		// Find the oldest cache time
 		var oldestCache time.Time
 		for _, t := range m.cachedAt {
@@ -1000,29 +2212,98 @@ func (m Model) viewFileList() string {
 	}
 
 	if selectedCount > 0 {
-		s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Found %d files%s%s | Selected: %d (%s)", len(displayFiles), dedupeIndicator, cacheIndicator, selectedCount, formatSize(selectedSize))))
+		s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Found %d files%s%s%s | Selected: %d (%s)", len(displayFiles), dedupeIndicator, cacheIndicator, m.skipIndicator(), selectedCount, formatSize(selectedSize))))
 	} else {
-		s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Found %d files (%s total)%s%s", len(displayFiles), formatSize(totalSize), dedupeIndicator, cacheIndicator)))
+		s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Found %d files (%s total)%s%s%s", len(displayFiles), formatSize(totalSize), dedupeIndicator, cacheIndicator, m.skipIndicator())))
 	}
 	s.WriteString("\n")
+	m.renderListWarnings(&s)
+	m.renderRestorePrompt(&s)
 
 	// Render the file list using the shared helper
-	m.renderFileList(&s, displayFiles, fileListConfig{
+	help := "j/k:move | gg/G:top/bottom | Space:toggle | a:all | ]/[:next/prev selected | i:info | b:file types | u:dedupe | h:hide downloaded | p:paths | m:modified since | F:browse folders | S:save selection | y:copy links | Y:copy summary | [N]t:select top N | o:output dir | O:open folder | x:skip existing | r:refresh | Enter:download | /:search | n/s/d/f/w:sort | q:quit"
+	if m.browsing {
+		help = "j/k:move | Enter/l:open folder | Backspace:up | Space:toggle | a:all here | ]/[:next/prev selected | i:info | F:flat list | h:hide downloaded | m:modified since | S:save selection | o:output dir | x:skip existing | r:refresh | Enter:download selected | /:search | n/s/d/f/w:sort | q:quit"
+	}
+	rows, counts := m.listRows(displayFiles)
+	m.renderFileList(&s, rows, fileListConfig{
 		showSortIndicators: true,
-		helpText:           "j/k:move | gg/G:top/bottom | Space:toggle | a:all | i:info | u:dedupe | r:refresh | Enter:download | /:search | n/s/d:sort | q:quit",
+		helpText:           m.listHelp(help),
+		files:              displayFiles,
+		folderCounts:       counts,
 	})
+	if m.showTypesPopup {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderTypesPopup())
+	}
 
 	return s.String()
 }
 
+// maxWarningLines is how many listing warnings the banner shows before
+// summarizing the rest
+const maxWarningLines = 3
+
+// warningLines is the height of the listing warnings banner and restore
+// prompt in the current view
+func (m Model) warningLines() int {
+	if m.view != ViewFileList {
+		return 0
+	}
+	n := 0
+	if len(m.listWarnings) > 0 {
+		n = 1 + min(len(m.listWarnings), maxWarningLines+1)
+	}
+	if m.savedSelection != nil {
+		n++
+	}
+	return n
+}
+
+// renderListWarnings draws the banner for folders that failed to list
+func (m Model) renderListWarnings(s *strings.Builder) {
+	if len(m.listWarnings) == 0 {
+		return
+	}
+
+	s.WriteString(WarningStyle.Render(fmt.Sprintf("Listing completed with %d warning(s), some files may be missing (Esc to dismiss, r to retry):", len(m.listWarnings))))
+	s.WriteString("\n")
+	width := max(m.width-4, 40)
+	for i, w := range m.listWarnings {
+		if i == maxWarningLines {
+			s.WriteString(DimStyle.Render(fmt.Sprintf("  ...and %d more", len(m.listWarnings)-maxWarningLines)))
+			s.WriteString("\n")
+			break
+		}
+		s.WriteString(DimStyle.Render("  " + truncateAndPad(w, width)))
+		s.WriteString("\n")
+	}
+}
+
 func (m Model) viewSearch() string {
 	var s strings.Builder
 
-	s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Search in %d files:", len(m.allFiles))))
+	scope := "names and folder paths"
+	if m.searchNameOnly {
+		scope = "names only"
+	}
+	s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Search in %d files (%s):", len(m.allFiles), scope)))
 	s.WriteString("\n")
 	s.WriteString(m.searchInput.View())
 	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render("Enter to search (empty = all files) | Esc to go back"))
+	s.WriteString(HelpStyle.Render("Enter to search (empty = all files) | Tab: toggle name-only | Esc to go back"))
+
+	return s.String()
+}
+
+func (m Model) viewDestDir() string {
+	var s strings.Builder
+
+	s.WriteString(SubtitleStyle.Render("Download files to:"))
+	s.WriteString("\n")
+	s.WriteString(m.destInput.View())
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("Enter to save (created if missing) | Esc to cancel"))
 
 	return s.String()
 }
@@ -1031,11 +2312,21 @@ func (m Model) viewSearch() string {
 type fileListConfig struct {
 	showSortIndicators bool   // whether to show sort direction indicators in the header
 	helpText           string // help text to display at the bottom
+
+	// For the browse view: the files behind its folder rows, and the number
+	// of files under each folder row by row ID (nil for a flat list)
+	files        []drive.DriveFile
+	folderCounts map[string]int
 }
 
 // renderFileList renders a paginated file list with cursor, checkboxes, and metadata.
 // It handles the common rendering logic shared between viewFileList and viewFiles.
 func (m Model) renderFileList(s *strings.Builder, files []drive.DriveFile, config fileListConfig) {
+	if m.compact() {
+		m.renderCompactFileList(s, files, config)
+		return
+	}
+
 	// Calculate dynamic widths based on terminal width
 	width := m.width
 	if width < 80 {
@@ -1060,8 +2351,15 @@ func (m Model) renderFileList(s *strings.Builder, files []drive.DriveFile, confi
 			}
 			return ""
 		}
+		nameHeader := "Name" + sortIndicator(SortByName)
+		switch m.sortField {
+		case SortByFolder:
+			nameHeader = "Name (by folder" + sortIndicator(SortByFolder) + ")"
+		case SortByOwner:
+			nameHeader = "Name (by owner" + sortIndicator(SortByOwner) + ")"
+		}
 		header = fmt.Sprintf("       %s %10s %12s",
-			padRight("Name"+sortIndicator(SortByName), nameWidth),
+			padRight(nameHeader, nameWidth),
 			"Size"+sortIndicator(SortBySize),
 			"Modified"+sortIndicator(SortByDate))
 	} else {
@@ -1073,27 +2371,7 @@ func (m Model) renderFileList(s *strings.Builder, files []drive.DriveFile, confi
 	s.WriteString("\n")
 
 	// Pagination
-	visibleStart := 0
-	visibleEnd := len(files)
-	maxVisible := m.height - 12
-	if maxVisible < 5 {
-		maxVisible = 10
-	}
-
-	if len(files) > maxVisible {
-		visibleStart = m.fileCursor - maxVisible/2
-		if visibleStart < 0 {
-			visibleStart = 0
-		}
-		visibleEnd = visibleStart + maxVisible
-		if visibleEnd > len(files) {
-			visibleEnd = len(files)
-			visibleStart = visibleEnd - maxVisible
-			if visibleStart < 0 {
-				visibleStart = 0
-			}
-		}
-	}
+	visibleStart, visibleEnd := m.visibleRange(len(files))
 
 	// File rows
 	for i := visibleStart; i < visibleEnd; i++ {
@@ -1103,15 +2381,15 @@ func (m Model) renderFileList(s *strings.Builder, files []drive.DriveFile, confi
 			cursor = "> "
 		}
 
-		checkbox := "[ ]"
-		if m.selectedFiles[f.ID] {
-			checkbox = "[x]"
-		}
+		checkbox := m.rowCheckbox(config.files, f)
 
-		// Show green square if file exists locally
+		// Show green square if file exists locally, orange if a partial download can be resumed
 		existsIcon := "  "
-		if m.fileExistsLocally(f) {
+		switch m.fileExistsCache[f.ID] {
+		case drive.LocalComplete:
 			existsIcon = SuccessStyle.Render("■") + " "
+		case drive.LocalPartial:
+			existsIcon = WarningStyle.Render("■") + " "
 		}
 
 		dateStr := ""
@@ -1123,7 +2401,7 @@ func (m Model) renderFileList(s *strings.Builder, files []drive.DriveFile, confi
 			cursor,
 			checkbox,
 			existsIcon,
-			truncateAndPad(f.DisplayName(), nameWidth),
+			truncateAndPad(m.rowName(f, config.folderCounts), nameWidth),
 			formatSize(f.Size),
 			dateStr)
 
@@ -1141,18 +2419,49 @@ func (m Model) renderFileList(s *strings.Builder, files []drive.DriveFile, confi
 	// Show info popup if active
 	if m.showInfoPopup && m.fileCursor < len(files) {
 		s.WriteString("\n\n")
-		s.WriteString(m.renderInfoPopup(files[m.fileCursor]))
+		s.WriteString(m.renderRowInfo(files[m.fileCursor], config))
 	}
 }
 
+// visibleRange returns the [start, end) window of rows shown for a list of n files,
+// keeping the cursor roughly centered when the list is longer than the screen.
+func (m Model) visibleRange(n int) (int, int) {
+	visibleStart := 0
+	visibleEnd := n
+	maxVisible := m.height - 12 - m.warningLines()
+	if m.compact() {
+		maxVisible = max(m.height-compactChromeLines-m.warningLines(), 1)
+	} else if maxVisible < 5 {
+		maxVisible = 10
+	}
+
+	if n > maxVisible {
+		visibleStart = m.fileCursor - maxVisible/2
+		if visibleStart < 0 {
+			visibleStart = 0
+		}
+		visibleEnd = visibleStart + maxVisible
+		if visibleEnd > n {
+			visibleEnd = n
+			visibleStart = visibleEnd - maxVisible
+			if visibleStart < 0 {
+				visibleStart = 0
+			}
+		}
+	}
+
+	return visibleStart, visibleEnd
+}
+
 func (m Model) viewFiles() string {
 	var s strings.Builder
 
 	// Use deduped files if mode is enabled
-	displayFiles := m.filteredFiles
+	baseFiles := m.filteredFiles
 	if m.showDeduped && len(m.dedupedFilteredFiles) > 0 {
-		displayFiles = m.dedupedFilteredFiles
+		baseFiles = m.dedupedFilteredFiles
 	}
+	displayFiles := m.getDisplayFilteredFiles()
 
 	selectedCount := 0
 	var selectedSize int64
@@ -1166,17 +2475,19 @@ func (m Model) viewFiles() string {
 	// Show dedupe indicator if active
 	dedupeIndicator := ""
 	if m.showDeduped {
-		dedupeIndicator = fmt.Sprintf(" [DEDUPED: %d → %d]", len(m.filteredFiles), len(displayFiles))
+		dedupeIndicator = fmt.Sprintf(" [DEDUPED: %d → %d]", len(m.filteredFiles), len(baseFiles))
 	}
+	dedupeIndicator += m.sinceIndicator() + m.globIndicator()
+	dedupeIndicator += m.hiddenIndicator(len(m.listFilters(baseFiles)), len(displayFiles))
 
-	s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Matching files: %d/%d selected (%s)%s",
-		selectedCount, len(displayFiles), formatSize(selectedSize), dedupeIndicator)))
+	s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Matching files: %d/%d selected (%s)%s%s",
+		selectedCount, len(displayFiles), formatSize(selectedSize), dedupeIndicator, m.skipIndicator())))
 	s.WriteString("\n")
 
 	// Render the file list using the shared helper
 	m.renderFileList(&s, displayFiles, fileListConfig{
 		showSortIndicators: false,
-		helpText:           "j/k:move | gg/G:top/bottom | Space:toggle | a:all | i:info | u:dedupe | Enter:download | Esc:back | q:quit",
+		helpText:           m.listHelp("j/k:move | gg/G:top/bottom | Space:toggle | a:all | ]/[:next/prev selected | i:info | u:dedupe | h:hide downloaded | p:paths | m:modified since | S:save selection | y:copy links | Y:copy summary | [N]t:select top N | o:output dir | O:open folder | x:skip existing | Enter:download | Esc:back | q:quit"),
 	})
 
 	return s.String()
@@ -1186,88 +2497,154 @@ func (m Model) viewDownloading() string {
 	var s strings.Builder
 
 	m.progressMu.Lock()
-	completed := m.completedCount
-	total := m.totalToDownload
+	overall := m.batch.Progress()
 	progress := make(map[string]drive.DownloadProgress)
 	for k, v := range m.fileProgress {
 		progress[k] = v
 	}
 	m.progressMu.Unlock()
 
-	// Calculate overall progress
-	var totalBytes, loadedBytes int64
-	for _, f := range m.downloadingFiles {
-		totalBytes += f.Size
-		if prog, ok := progress[f.ID]; ok {
-			loadedBytes += prog.BytesLoaded
-		}
-	}
-
-	overallPct := 0.0
-	if totalBytes > 0 {
-		overallPct = float64(loadedBytes) / float64(totalBytes) * 100
+	overallPct := overall.Percent()
+	s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Downloading... %d/%d files (%.1f%%)", overall.FilesDone, overall.FilesTotal, overallPct)))
+	if limit, ok := m.pool.throttled(); ok {
+		s.WriteString(" " + WarningStyle.Render(fmt.Sprintf("[quota errors, %d of %d at a time]", limit, m.maxConcurrent)))
+	} else {
+		s.WriteString(" " + DimStyle.Render(fmt.Sprintf("[%d at a time]", m.maxConcurrent)))
 	}
-
-	s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Downloading... %d/%d files (%.1f%%)", completed, total, overallPct)))
 	s.WriteString("\n")
+	m.renderOverwritePrompt(&s)
 
-	// Calculate dynamic widths based on terminal width
+	// Calculate dynamic widths based on terminal width; the compact layout
+	// uses the real width and narrower bars
 	width := m.width
-	if width < 80 {
+	barWidth, statusWidth := 20, 30 // "Done", "Failed", "Pending", or small progress bar
+	if m.compact() {
+		barWidth, statusWidth = 10, 18
+	} else if width < 80 {
 		width = 80
 	}
-	progressBarWidth := width - 30
-	if progressBarWidth < 20 {
-		progressBarWidth = 20
-	}
+	progressBarWidth := max(width-42, min(20, width/2))
 
 	// Overall progress bar
 	s.WriteString(renderProgressBar(overallPct, progressBarWidth))
-	s.WriteString(fmt.Sprintf(" %s / %s", formatSize(loadedBytes), formatSize(totalBytes)))
+	s.WriteString(fmt.Sprintf(" %s / %s  ETA %s", formatSize(overall.LoadedBytes), formatSize(overall.TotalBytes), batchETA(overall, time.Since(m.batchStarted))))
 	s.WriteString("\n\n")
 
 	// Calculate name width for file list
 	// Reserve space for: name + space + status (progress bar or status text)
-	statusWidth := 30 // "Done", "Failed", "Pending", or small progress bar
-	nameWidth := width - statusWidth - 2
-	if nameWidth < 20 {
-		nameWidth = 20
-	}
+	nameWidth := max(width-statusWidth-2, 10)
 
-	// Individual file progress - show all files being downloaded
+	// Files that have started, followed by the queue in the order it will run
+	queued := make(map[string]bool)
+	for _, f := range m.queue.snapshot() {
+		queued[f.ID] = true
+	}
+	activeRow := make(map[string]int)
+	active := m.inProgress()
+	for i, f := range active {
+		activeRow[f.ID] = i
+	}
 	for _, f := range m.downloadingFiles {
+		if queued[f.ID] {
+			continue
+		}
 		prog, hasProgress := progress[f.ID]
 
+		// A failed file jumped to with ]/[ takes the cursor from the queue
+		cursor := "  "
+		row, ok := activeRow[f.ID]
+		if f.ID == m.failedFocus || (m.failedFocus == "" && ok && row == m.queueCursor) {
+			cursor = "> "
+		}
+
 		var status string
 		if hasProgress {
 			if prog.Error != nil {
 				status = ErrorStyle.Render("Failed")
+			} else if prog.Cancelled {
+				status = WarningStyle.Render("Cancelled")
 			} else if prog.Skipped {
 				status = DimStyle.Render("Skipped")
 			} else if prog.Done {
 				status = SuccessStyle.Render("Done")
+			} else if prog.Retry > 0 {
+				status = WarningStyle.Render(fmt.Sprintf("retrying (%d/%d)...", prog.Retry, prog.MaxRetries))
+			} else if prog.TotalBytes <= 0 {
+				// Size unknown, so there is no percentage to show
+				status = fmt.Sprintf("%s %s", renderIndeterminateBar(barWidth), formatSize(prog.BytesLoaded))
 			} else {
-				pct := 0.0
-				if prog.TotalBytes > 0 {
-					pct = float64(prog.BytesLoaded) / float64(prog.TotalBytes) * 100
-				}
-				status = fmt.Sprintf("%s %.0f%%", renderProgressBar(pct, 20), pct)
+				pct := float64(prog.BytesLoaded) / float64(prog.TotalBytes) * 100
+				status = fmt.Sprintf("%s %.0f%%", renderProgressBar(pct, barWidth), pct)
 			}
 		} else {
 			status = DimStyle.Render("Pending")
 		}
 
-		s.WriteString(fmt.Sprintf("%s %s\n", truncateAndPad(f.DisplayName(), nameWidth), status))
+		line := cursor + truncateAndPad(f.DisplayName(), nameWidth-2)
+		if cursor == "> " {
+			line = SelectedStyle.Render(line)
+		}
+		s.WriteString(fmt.Sprintf("%s %s\n", line, status))
+	}
+
+	if len(queued) > 0 || m.queueFilter.Value() != "" {
+		s.WriteString("\n")
+		s.WriteString(SubtitleStyle.Render(fmt.Sprintf("Queue (%d pending)", len(queued))))
+		s.WriteString("\n")
+		if m.queueFilter.Focused() || m.queueFilter.Value() != "" {
+			s.WriteString(m.queueFilter.View())
+			s.WriteString("\n")
+		}
+		for i, f := range m.visibleQueue() {
+			selected := len(active)+i == m.queueCursor && m.failedFocus == ""
+			cursor := "  "
+			if selected {
+				cursor = "> "
+			}
+			line := cursor + truncateAndPad(f.DisplayName(), nameWidth-2) + " " + DimStyle.Render("Pending")
+			if selected {
+				line = SelectedStyle.Render(line)
+			}
+			s.WriteString(line + "\n")
+		}
 	}
 
 	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render("q:quit | Esc:cancel"))
+	if m.status != "" {
+		s.WriteString(DimStyle.Render(m.status))
+		s.WriteString("\n")
+	}
+	if m.cancelling {
+		s.WriteString(WarningStyle.Render("Cancelling, waiting for downloads to stop..."))
+		s.WriteString("\n")
+		s.WriteString(HelpStyle.Render("Esc/Ctrl+C again to force quit"))
+	} else {
+		s.WriteString(HelpStyle.Render(m.fitHelp("j/k:move cursor | ]/[:next/prev failed | J/K:reorder | d:cancel file | +/-:concurrency | /:filter | q:quit | Esc:cancel all")))
+	}
 
 	return s.String()
 }
 
+// renderIndeterminateBar draws a block that bounces across the bar, for
+// downloads whose total size is unknown. It animates with the download tick.
+func renderIndeterminateBar(width int) string {
+	const block = 4
+	width = max(width, block+1)
+	span := width - block
+	step := int(time.Now().UnixMilli()/100) % (2 * span)
+	pos := step
+	if step > span {
+		pos = 2*span - step
+	}
+
+	return DimStyle.Render(strings.Repeat("░", pos)) +
+		SuccessStyle.Render(strings.Repeat("█", block)) +
+		DimStyle.Render(strings.Repeat("░", width-block-pos))
+}
+
 // renderProgressBar creates a text-based progress bar
 func renderProgressBar(pct float64, width int) string {
+	width = max(width, 0)
 	filled := int(pct / 100 * float64(width))
 	if filled > width {
 		filled = width
@@ -1285,59 +2662,153 @@ func renderProgressBar(pct float64, width int) string {
 func (m Model) viewDone() string {
 	var s strings.Builder
 
-	s.WriteString(SuccessStyle.Render("Download complete!"))
+	if m.noFiles {
+		s.WriteString(WarningStyle.Render("Nothing to download"))
+	} else if m.capExceeded {
+		s.WriteString(WarningStyle.Render("Download refused: selection exceeds the size cap"))
+	} else if m.timedOut {
+		s.WriteString(ErrorStyle.Render("Timed out: the run's time limit was reached"))
+	} else if m.cancelled {
+		s.WriteString(WarningStyle.Render("Download cancelled"))
+	} else {
+		s.WriteString(SuccessStyle.Render("Download complete!"))
+	}
 	s.WriteString("\n\n")
 
-	successCount := 0
-	skippedCount := 0
-	errorCount := 0
-	var failedFiles []string
+	report := m.buildReport()
+	successCount := report.Succeeded
+	skippedCount := report.Skipped
+	errorCount := report.Failed
+	cancelledCount := report.Cancelled
 
-	m.progressMu.Lock()
-	for _, f := range m.downloadingFiles {
-		if prog, ok := m.fileProgress[f.ID]; ok {
-			if prog.Error != nil {
-				errorCount++
-				failedFiles = append(failedFiles, fmt.Sprintf("  %s: %v", f.DisplayName(), prog.Error))
-			} else if prog.Skipped {
-				skippedCount++
-			} else if prog.Done {
-				successCount++
-			}
+	s.WriteString(fmt.Sprintf("Successfully downloaded: %d files\n", successCount))
+	if skippedCount > 0 {
+		s.WriteString(DimStyle.Render(fmt.Sprintf("Skipped (already exist): %d files\n", skippedCount)))
+	}
+	if errorCount > 0 {
+		s.WriteString(ErrorStyle.Render(fmt.Sprintf("Failed: %d files%s\n", errorCount, failureBreakdown(report))))
+		if advice := m.quotaAdvice(); advice != "" {
+			s.WriteString(WarningStyle.Render(advice))
+			s.WriteString("\n")
 		}
 	}
-	m.progressMu.Unlock()
+	if cancelledCount > 0 {
+		s.WriteString(WarningStyle.Render(fmt.Sprintf("Cancelled: %d files\n", cancelledCount)))
+	}
+	if !m.batchStarted.IsZero() && !m.batchFinished.IsZero() {
+		s.WriteString(DimStyle.Render(m.batchStats(report)))
+	}
+	if m.driveClient != nil {
+		s.WriteString(DimStyle.Render(fmt.Sprintf("Made %s API calls\n", formatCount(m.driveClient.APICalls()))))
+	}
+	s.WriteString("\n")
 
-	if len(failedFiles) > 0 {
-		s.WriteString(ErrorStyle.Render("Failed downloads:"))
-		s.WriteString("\n")
-		for _, line := range failedFiles {
-			s.WriteString(ErrorStyle.Render(line))
+	footer := m.doneFooter(report)
+	m.renderDoneFiles(&s, report, s.String()+footer)
+	s.WriteString(footer)
+
+	return s.String()
+}
+
+// doneFooter renders the part of the Done screen below the file list
+func (m Model) doneFooter(report Report) string {
+	var s strings.Builder
+
+	if len(report.DeletedUpstream) > 0 {
+		s.WriteString(WarningStyle.Render(fmt.Sprintf("\nDeleted upstream (local copies kept): %d files\n", len(report.DeletedUpstream))))
+		for _, name := range report.DeletedUpstream {
+			s.WriteString(DimStyle.Render("  " + name))
 			s.WriteString("\n")
 		}
-		s.WriteString("\n")
 	}
 
-	s.WriteString(fmt.Sprintf("Successfully downloaded: %d files\n", successCount))
-	if skippedCount > 0 {
-		s.WriteString(DimStyle.Render(fmt.Sprintf("Skipped (already exist): %d files\n", skippedCount)))
+	s.WriteString(DimStyle.Render(fmt.Sprintf("\nFiles saved to: %s", m.outputDir())))
+	if m.archivePath != "" {
+		s.WriteString(DimStyle.Render(fmt.Sprintf("\nArchive written to: %s", m.archivePath)))
 	}
-	if errorCount > 0 {
-		s.WriteString(ErrorStyle.Render(fmt.Sprintf("Failed: %d files\n", errorCount)))
+	for _, e := range m.archiveErrors {
+		s.WriteString("\n")
+		s.WriteString(ErrorStyle.Render(e))
 	}
-
-	destDir := m.destDir
-	if destDir == "" {
-		destDir = "."
+	if m.manifestError != nil {
+		s.WriteString("\n")
+		s.WriteString(ErrorStyle.Render(m.manifestError.Error()))
+	} else if m.manifest {
+		s.WriteString(DimStyle.Render(fmt.Sprintf("\nManifest: %s", filepath.Join(m.outputDir(), drive.ManifestName))))
+	}
+	if m.reportError != nil {
+		s.WriteString("\n")
+		s.WriteString(ErrorStyle.Render(m.reportError.Error()))
+	} else if m.reportPath != "" {
+		s.WriteString(DimStyle.Render(fmt.Sprintf("\nReport written to: %s", m.reportPath)))
 	}
-	s.WriteString(DimStyle.Render(fmt.Sprintf("\nFiles saved to: %s", destDir)))
 
 	s.WriteString("\n\n")
-	s.WriteString(HelpStyle.Render("Press q or Ctrl+C to quit"))
+	help := "O:open folder | q/Ctrl+C:quit"
+	if len(report.Files) > 0 {
+		help = "j/k:scroll | f:failed | s:skipped | a:all | " + help
+	}
+	s.WriteString(HelpStyle.Render(m.fitHelp(help)))
 
 	return s.String()
 }
 
+// batchStats summarizes bytes transferred, wall-clock time, and throughput for the last batch
+func (m Model) batchStats(report Report) string {
+	var downloaded int64
+	for _, f := range report.Files {
+		if f.Status == "done" {
+			downloaded += f.Bytes
+		}
+	}
+
+	elapsed := m.batchFinished.Sub(m.batchStarted)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(downloaded) / elapsed.Seconds()
+	}
+
+	return fmt.Sprintf("\nDownloaded %s in %s (%s/s, %d concurrent)\n",
+		formatSize(downloaded), formatDuration(elapsed), formatSize(int64(rate)), m.maxConcurrent)
+}
+
+// formatCount formats a non-negative n with thousands separators, e.g. "1,240"
+func formatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// etaWarmup is how long a batch runs before its ETA is shown, since the
+// first moments' throughput says little about the rest
+const etaWarmup = time.Second
+
+// batchETA estimates the time left in a batch from its average throughput
+// so far, as "m:ss" (or "h:mm:ss"), or "--:--" when there is no estimate yet
+func batchETA(p drive.BatchProgress, elapsed time.Duration) string {
+	if elapsed < etaWarmup || p.LoadedBytes <= 0 || p.TotalBytes <= 0 {
+		return "--:--"
+	}
+	remaining := max(p.TotalBytes-p.LoadedBytes, 0)
+	left := time.Duration(float64(elapsed) * float64(remaining) / float64(p.LoadedBytes)).Round(time.Second)
+
+	h, m, s := int(left.Hours()), int(left.Minutes())%60, int(left.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// formatDuration rounds d to a precision that suits its length
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
 // renderInfoPopup renders a popup with file metadata
 func (m Model) renderInfoPopup(f drive.DriveFile) string {
 	var s strings.Builder
@@ -1358,6 +2829,16 @@ func (m Model) renderInfoPopup(f drive.DriveFile) string {
 	}
 	s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("File ID"), f.ID))
 	s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Folder ID"), f.FolderID))
+	if f.RootFolderID != "" {
+		s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Source Folder"), rootFolderLabel(f)))
+	}
+	if f.Owner != "" || f.OwnerEmail != "" {
+		owner := f.Owner
+		if f.OwnerEmail != "" {
+			owner = strings.TrimSpace(fmt.Sprintf("%s <%s>", f.Owner, f.OwnerEmail))
+		}
+		s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Owner"), owner))
+	}
 	s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Size"), formatSize(f.Size)))
 	s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("MIME Type"), f.MimeType))
 
@@ -1367,26 +2848,27 @@ func (m Model) renderInfoPopup(f drive.DriveFile) string {
 	if !f.ModifiedTime.IsZero() {
 		s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Modified"), f.ModifiedTime.Format("2006-01-02 15:04:05")))
 	}
+	m.renderRevisions(&s, f)
 
 	s.WriteString("\n")
 	s.WriteString(DimStyle.Render(strings.Repeat("-", boxWidth)))
 	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render("Press i or Esc to close"))
+	s.WriteString(HelpStyle.Render(m.infoPopupHelp(f)))
 
 	return s.String()
 }
 
-func formatSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+// rootFolderLabel returns a display label for the top-level folder a file came from
+func rootFolderLabel(f drive.DriveFile) string {
+	if f.RootFolderName != "" {
+		return fmt.Sprintf("%s (%s)", f.RootFolderName, f.RootFolderID)
 	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	return f.RootFolderID
+}
+
+// formatSize formats a byte count with a binary unit, e.g. "1.5 MB"
+func formatSize(bytes int64) string {
+	return drive.FormatSize(bytes)
 }
 
 func formatTimeAgo(t time.Time) string {
@@ -1467,33 +2949,59 @@ func truncate(s string, max int) string {
 	return string(runes[:max-3]) + "..."
 }
 
-// fileExistsLocally checks if a file already exists in the destination directory with the same size
-// Uses cached result if available
-func (m Model) fileExistsLocally(f drive.DriveFile) bool {
-	if exists, ok := m.fileExistsCache[f.ID]; ok {
-		return exists
+// checkFileExistsLocally performs the actual filesystem check using the exist policy
+func (m Model) checkFileExistsLocally(f drive.DriveFile) drive.LocalState {
+	filePath, err := m.driveClient.DestPath(m.outputDir(), f)
+	if err != nil {
+		return drive.LocalMissing
 	}
-	return false
+	return drive.LocalFileState(filePath, f, m.existPolicy)
 }
 
-// checkFileExistsLocally performs the actual filesystem check
-func (m Model) checkFileExistsLocally(f drive.DriveFile) bool {
-	destDir := m.destDir
-	if destDir == "" {
-		destDir = "./output"
+// outputDir returns the download directory, defaulting to ./output
+func (m Model) outputDir() string {
+	if m.destDir == "" {
+		return filepath.Join(".", "output")
 	}
+	return m.destDir
+}
 
-	fullPath := destDir
-	if f.Path != "" {
-		fullPath = fmt.Sprintf("%s/%s", destDir, f.Path)
+// localDir returns the directory a file is (or will be) downloaded into
+func (m Model) localDir(f drive.DriveFile) string {
+	return filepath.Join(m.outputDir(), filepath.FromSlash(f.Path))
+}
+
+// openFolder opens the folder holding the file under the cursor (or the
+// folder under it, when browsing), or the output directory when there is no
+// such file or its folder doesn't exist yet
+func (m *Model) openFolder(files []drive.DriveFile) {
+	dir := m.localDir(drive.DriveFile{})
+	if m.fileCursor < len(files) {
+		f := files[m.fileCursor]
+		var d string
+		if isFolderRow(f) {
+			d = m.localDir(drive.DriveFile{Path: folderRowPath(f)})
+		} else if path, err := m.driveClient.DestPath(m.outputDir(), f); err == nil {
+			// The output structure may not follow Drive's folders
+			d = filepath.Dir(path)
+		}
+		if d != "" && dirExists(d) {
+			dir = d
+		}
 	}
-	filePath := fmt.Sprintf("%s/%s", fullPath, f.Name)
 
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return false
+	if err := openInFileManager(dir); err != nil {
+		m.err = err
+		return
 	}
-	return info.Size() == f.Size
+	m.err = nil
+	m.status = fmt.Sprintf("Opened %s", dir)
+}
+
+// dirExists reports whether path is an existing directory
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
 
 // updateFileExistsCache updates the file existence cache for all files
@@ -1503,6 +3011,30 @@ func (m *Model) updateFileExistsCache() {
 	}
 }
 
+// refreshFinishedExists re-checks files whose downloads have finished since
+// the last tick, so the file list marks them as downloaded without waiting
+// for the whole batch. Files already known to be complete aren't stat'ed again.
+func (m *Model) refreshFinishedExists() {
+	// Archive-only downloads are removed once archived, so they never show up
+	if m.archiveOnly {
+		return
+	}
+
+	var finished []drive.DriveFile
+	m.progressMu.Lock()
+	for _, f := range m.downloadingFiles {
+		prog := m.fileProgress[f.ID]
+		if prog.Done && prog.Error == nil && !prog.Cancelled && m.fileExistsCache[f.ID] != drive.LocalComplete {
+			finished = append(finished, f)
+		}
+	}
+	m.progressMu.Unlock()
+
+	for _, f := range finished {
+		m.fileExistsCache[f.ID] = m.checkFileExistsLocally(f)
+	}
+}
+
 // dedupeFiles groups files by folder and common prefix, returning only the smallest version of each group
 func dedupeFiles(files []drive.DriveFile) []drive.DriveFile {
 	// Group files by their folder path
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArchiveFormat selects what -archive bundles downloaded files into.
+type ArchiveFormat string
+
+const (
+	// ArchiveNone leaves downloads as loose files only.
+	ArchiveNone ArchiveFormat = ""
+	// ArchiveZip writes a deflated .zip archive.
+	ArchiveZip ArchiveFormat = "zip"
+	// ArchiveTarGz writes a gzip-compressed tarball.
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+// ParseArchiveFormat parses the -archive flag value
+func ParseArchiveFormat(s string) (ArchiveFormat, error) {
+	switch ArchiveFormat(s) {
+	case ArchiveNone, ArchiveZip, ArchiveTarGz:
+		return ArchiveFormat(s), nil
+	}
+	return ArchiveNone, fmt.Errorf("unknown archive format %q (want zip or tar.gz)", s)
+}
+
+// archiveName returns a timestamped file name for a batch archive
+func archiveName(format ArchiveFormat, t time.Time) string {
+	return "google-drive-dl-" + t.Format("20060102-150405") + "." + string(format)
+}
+
+// archiver streams downloaded files into a single archive. Archive writers
+// aren't safe for concurrent use, so downloadFiles feeds it from one goroutine.
+type archiver struct {
+	f  *os.File
+	zw *zip.Writer
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+// createArchive creates the archive file at path in the given format
+func createArchive(path string, format ArchiveFormat) (*archiver, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create archive: %w", err)
+	}
+
+	a := &archiver{f: f}
+	switch format {
+	case ArchiveZip:
+		a.zw = zip.NewWriter(f)
+	case ArchiveTarGz:
+		a.gz = gzip.NewWriter(f)
+		a.tw = tar.NewWriter(a.gz)
+	default:
+		f.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("unknown archive format %q", format)
+	}
+	return a, nil
+}
+
+// add copies the file at localPath into the archive as name, a '/'-separated
+// path relative to the archive root
+func (a *archiver) add(name, localPath string) error {
+	src, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("unable to archive %s: %w", name, err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to archive %s: %w", name, err)
+	}
+
+	var w io.Writer
+	if a.zw != nil {
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return fmt.Errorf("unable to archive %s: %w", name, err)
+		}
+		hdr.Name = name
+		hdr.Method = zip.Deflate
+		if w, err = a.zw.CreateHeader(hdr); err != nil {
+			return fmt.Errorf("unable to archive %s: %w", name, err)
+		}
+	} else {
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return fmt.Errorf("unable to archive %s: %w", name, err)
+		}
+		hdr.Name = name
+		if err := a.tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("unable to archive %s: %w", name, err)
+		}
+		w = a.tw
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("unable to archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// close finishes the archive and closes the underlying file
+func (a *archiver) close() error {
+	var err error
+	if a.zw != nil {
+		err = a.zw.Close()
+	} else {
+		err = a.tw.Close()
+		if gzErr := a.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	if closeErr := a.f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to finish archive: %w", err)
+	}
+	return nil
+}
+
+// archiveItem is a finished download waiting to be archived
+type archiveItem struct {
+	name      string // entry name inside the archive
+	localPath string
+	remove    bool // delete the loose file once it is archived
+}
+
+// runArchiver adds every item to a until items is closed, then closes a. It
+// returns one message per file that could not be archived.
+func runArchiver(a *archiver, items <-chan archiveItem) []string {
+	var errs []string
+	for item := range items {
+		if err := a.add(item.name, item.localPath); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if item.remove {
+			os.Remove(item.localPath)
+		}
+	}
+	if err := a.close(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	return errs
+}
+
+// archiveEntryName returns localPath relative to destDir with '/' separators,
+// preserving the Drive folder hierarchy inside the archive
+func archiveEntryName(destDir, localPath string) (string, error) {
+	rel, err := filepath.Rel(destDir, localPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
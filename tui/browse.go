@@ -0,0 +1,312 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google-drive-dl/drive"
+)
+
+// The browse view shows the file list one folder at a time. Listings only
+// hold files, so its folders are derived from their paths: a folder that
+// contains no files anywhere below it doesn't appear.
+
+// folderRowPrefix starts the IDs of folder rows, which aren't Drive IDs. The
+// rest of the ID is the folder's location.
+const folderRowPrefix = "folder:"
+
+// fileLocation returns where a file sits in the browse view: its root folder
+// ID followed by its folder path
+func fileLocation(f drive.DriveFile) string {
+	if p := strings.Trim(f.Path, "/"); p != "" {
+		return f.RootFolderID + "/" + p
+	}
+	return f.RootFolderID
+}
+
+// isFolderRow reports whether f is a folder row of the browse view
+func isFolderRow(f drive.DriveFile) bool {
+	return strings.HasPrefix(f.ID, folderRowPrefix)
+}
+
+// browseTop returns the location the browse view starts at and can't go
+// above: the root folder when there is only one, otherwise the list of roots
+func (m Model) browseTop() string {
+	top := ""
+	for i, f := range m.allFiles {
+		if i > 0 && f.RootFolderID != top {
+			return ""
+		}
+		top = f.RootFolderID
+	}
+	return top
+}
+
+// listRows returns the rows of the file list view for the files in view,
+// which are the files themselves unless browsing. The map holds the number
+// of files under each folder row, by row ID.
+func (m Model) listRows(files []drive.DriveFile) ([]drive.DriveFile, map[string]int) {
+	if !m.browsing {
+		return files, nil
+	}
+
+	folders := make(map[string]*drive.DriveFile)
+	counts := make(map[string]int)
+	var direct []drive.DriveFile
+	for _, f := range files {
+		loc := fileLocation(f)
+		if loc == m.browsePath {
+			direct = append(direct, f)
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(loc, m.browsePath+"/")
+		if m.browsePath == "" {
+			rest, ok = loc, true
+		}
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, "/")
+		key := strings.TrimPrefix(m.browsePath+"/"+name, "/")
+		row, seen := folders[key]
+		if !seen {
+			row = &drive.DriveFile{
+				ID:           folderRowPrefix + key,
+				Name:         name,
+				Path:         m.browsePath,
+				MimeType:     "application/vnd.google-apps.folder",
+				RootFolderID: f.RootFolderID,
+			}
+			if m.browsePath == "" {
+				row.Name = cmp.Or(f.RootFolderName, f.RootFolderID)
+			}
+			folders[key] = row
+		}
+		row.Size += f.Size
+		if f.ModifiedTime.After(row.ModifiedTime) {
+			row.ModifiedTime = f.ModifiedTime
+		}
+		counts[row.ID]++
+	}
+
+	rows := make([]drive.DriveFile, 0, len(folders)+len(direct))
+	for _, row := range folders {
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b drive.DriveFile) int {
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return append(rows, direct...), counts
+}
+
+// folderRowPath returns the Drive path of a folder row below its root
+// folder, which is where its files are saved under the output directory
+func folderRowPath(row drive.DriveFile) string {
+	_, path, _ := strings.Cut(strings.TrimPrefix(row.ID, folderRowPrefix), "/")
+	return path
+}
+
+// filesUnder returns the files in files that a row stands for: the file
+// itself, or everything anywhere below a folder row
+func filesUnder(files []drive.DriveFile, row drive.DriveFile) []drive.DriveFile {
+	if !isFolderRow(row) {
+		return []drive.DriveFile{row}
+	}
+	key := strings.TrimPrefix(row.ID, folderRowPrefix)
+	var under []drive.DriveFile
+	for _, f := range files {
+		if loc := fileLocation(f); loc == key || strings.HasPrefix(loc, key+"/") {
+			under = append(under, f)
+		}
+	}
+	return under
+}
+
+// filesInView returns the files the list's bulk actions apply to: all of
+// them, or when browsing, those in or below the current folder
+func (m Model) filesInView(files []drive.DriveFile) []drive.DriveFile {
+	if !m.browsing || m.browsePath == "" {
+		return files
+	}
+	return filesUnder(files, drive.DriveFile{ID: folderRowPrefix + m.browsePath})
+}
+
+// toggleRow selects every file a row stands for, or deselects them if all
+// of them were already selected
+func (m *Model) toggleRow(files []drive.DriveFile, row drive.DriveFile) {
+	under := filesUnder(files, row)
+	all := len(under) > 0
+	for _, f := range under {
+		all = all && m.selectedFiles[f.ID]
+	}
+	for _, f := range under {
+		m.selectedFiles[f.ID] = !all
+	}
+}
+
+// rowCheckbox returns the checkbox of a list row; a folder shows [-] when
+// only some of its files are selected
+func (m Model) rowCheckbox(files []drive.DriveFile, row drive.DriveFile) string {
+	if !isFolderRow(row) {
+		if m.selectedFiles[row.ID] {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	selected := 0
+	under := filesUnder(files, row)
+	for _, f := range under {
+		if m.selectedFiles[f.ID] {
+			selected++
+		}
+	}
+	switch {
+	case selected == 0:
+		return "[ ]"
+	case selected == len(under):
+		return "[x]"
+	}
+	return "[-]"
+}
+
+// rowName returns the name shown for a list row. The browse view, which has
+// folder counts, shows names relative to the current folder, and folders with
+// their file count. The flat list shows the Drive path too unless hidePaths.
+func (m Model) rowName(row drive.DriveFile, counts map[string]int) string {
+	if counts == nil {
+		if m.hidePaths {
+			return row.Name
+		}
+		return row.DisplayName()
+	}
+	if isFolderRow(row) {
+		if n := counts[row.ID]; n != 1 {
+			return fmt.Sprintf("▸ %s/ (%d files)", row.Name, n)
+		}
+		return fmt.Sprintf("▸ %s/ (1 file)", row.Name)
+	}
+	return "  " + row.Name
+}
+
+// toggleBrowse switches between the flat list and the browse view
+func (m *Model) toggleBrowse() {
+	m.browsing = !m.browsing
+	m.browsePath = m.browseTop()
+	m.fileCursor = 0
+	if m.browsing {
+		m.status = "Browsing folders (Enter/l: open, Backspace: up)"
+	} else {
+		m.status = "Showing all files"
+	}
+}
+
+// openFolderRow moves the browse view into a folder row
+func (m *Model) openFolderRow(row drive.DriveFile) {
+	m.browsePath = strings.TrimPrefix(row.ID, folderRowPrefix)
+	m.fileCursor = 0
+}
+
+// browseUp moves the browse view to the parent folder, keeping the cursor
+// on the folder it came from. It reports false at the top.
+func (m *Model) browseUp() bool {
+	top := m.browseTop()
+	if m.browsePath == top {
+		return false
+	}
+	from := folderRowPrefix + m.browsePath
+	if i := strings.LastIndexByte(m.browsePath, '/'); i >= 0 {
+		m.browsePath = m.browsePath[:i]
+	} else {
+		m.browsePath = ""
+	}
+
+	rows, _ := m.listRows(m.getDisplayFiles())
+	m.fileCursor = max(slices.IndexFunc(rows, func(f drive.DriveFile) bool { return f.ID == from }), 0)
+	return true
+}
+
+// browseIndicator names the current folder of the browse view in the list header
+func (m Model) browseIndicator() string {
+	if !m.browsing {
+		return ""
+	}
+	if m.browsePath == "" {
+		return " [folders]"
+	}
+	return " [in " + m.locationLabel(m.browsePath) + "]"
+}
+
+// locationLabel returns a browse location as a path starting with the root
+// folder's name
+func (m Model) locationLabel(loc string) string {
+	root, rest, _ := strings.Cut(loc, "/")
+	for _, f := range m.allFiles {
+		if f.RootFolderID == root {
+			root = cmp.Or(f.RootFolderName, root)
+			break
+		}
+	}
+	if rest == "" {
+		return root
+	}
+	return root + "/" + rest
+}
+
+// renderRowInfo draws the info popup for a list row
+func (m Model) renderRowInfo(row drive.DriveFile, config fileListConfig) string {
+	if isFolderRow(row) {
+		return m.renderFolderInfo(row, config.files)
+	}
+	return m.renderInfoPopup(row)
+}
+
+// renderFolderInfo draws the info popup for a folder row
+func (m Model) renderFolderInfo(row drive.DriveFile, files []drive.DriveFile) string {
+	var s strings.Builder
+
+	boxWidth := 60
+	if m.width > 70 {
+		boxWidth = m.width - 10
+	}
+
+	s.WriteString(BoxStyle.Render(TitleStyle.Render("Folder Information")))
+	s.WriteString("\n\n")
+
+	key := strings.TrimPrefix(row.ID, folderRowPrefix)
+	under := filesUnder(files, row)
+	subfolders := make(map[string]bool)
+	direct := 0
+	var latest time.Time
+	for _, f := range under {
+		loc := fileLocation(f)
+		if loc == key {
+			direct++
+		} else {
+			name, _, _ := strings.Cut(strings.TrimPrefix(loc, key+"/"), "/")
+			subfolders[name] = true
+		}
+		if f.ModifiedTime.After(latest) {
+			latest = f.ModifiedTime
+		}
+	}
+
+	s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Name"), row.Name))
+	s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Path"), m.locationLabel(key)))
+	s.WriteString(fmt.Sprintf("  %s: %d\n", SelectedStyle.Render("Subfolders"), len(subfolders)))
+	s.WriteString(fmt.Sprintf("  %s: %d here, %d in total\n", SelectedStyle.Render("Files"), direct, len(under)))
+	s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Size"), formatSize(row.Size)))
+	if !latest.IsZero() {
+		s.WriteString(fmt.Sprintf("  %s: %s\n", SelectedStyle.Render("Last modified"), latest.Format("2006-01-02 15:04:05")))
+	}
+
+	s.WriteString("\n")
+	s.WriteString(DimStyle.Render(strings.Repeat("-", boxWidth)))
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("Press i or Esc to close"))
+
+	return s.String()
+}
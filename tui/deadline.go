@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runTimedOutMsg is sent when the deadline of the run's parent context passes
+type runTimedOutMsg struct{}
+
+// waitForDeadline reports once ctx's deadline passes. Being cancelled
+// first, e.g. with Esc, sends nothing.
+func waitForDeadline(ctx context.Context) tea.Cmd {
+	if _, ok := ctx.Deadline(); !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		<-ctx.Done()
+		if ctx.Err() != context.DeadlineExceeded {
+			return nil
+		}
+		return runTimedOutMsg{}
+	}
+}
+
+// timeOut ends the run once its deadline passes: running downloads wind down
+// as on Esc, anything else goes straight to the Done screen. A run that had
+// already finished is left alone.
+func (m Model) timeOut() (tea.Model, tea.Cmd) {
+	if m.view == ViewDone {
+		return m, nil
+	}
+	m.timedOut = true
+	if m.downloading && !m.downloadDone {
+		if m.cancelling {
+			return m, nil
+		}
+		return m.beginShutdown()
+	}
+	m.listing = false
+	m.view = ViewDone
+	m.reportError = m.writeReport()
+	return m, nil
+}
+
+// lateAfterTimeout reports whether msg carries listing results that a
+// timed-out run drops, so it stays on the Done screen
+func (m Model) lateAfterTimeout(msg tea.Msg) bool {
+	if !m.timedOut {
+		return false
+	}
+	switch msg.(type) {
+	case errMsg, filesFromCacheMsg, filesPageMsg, filesLoadedMsg:
+		return true
+	}
+	return false
+}
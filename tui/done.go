@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"google-drive-dl/drive"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// doneFilter selects which files the Done screen lists
+type doneFilter int
+
+const (
+	doneAll doneFilter = iota
+	doneFailed
+	doneSkipped
+)
+
+// String returns the filter's label for the Done screen
+func (f doneFilter) String() string {
+	switch f {
+	case doneFailed:
+		return "failed"
+	case doneSkipped:
+		return "skipped"
+	}
+	return "all"
+}
+
+// doneEntries returns the report's files that pass the Done screen's filter
+func (m Model) doneEntries(report Report) []ReportFile {
+	if m.doneFilter == doneAll {
+		return report.Files
+	}
+	want := m.doneFilter.String()
+	var entries []ReportFile
+	for _, f := range report.Files {
+		if f.Status == want {
+			entries = append(entries, f)
+		}
+	}
+	return entries
+}
+
+// resetDoneList points the Done screen at the failures when there are any,
+// so they don't scroll away among the successes
+func (m *Model) resetDoneList() {
+	m.doneCursor = 0
+	m.doneFilter = doneAll
+	if m.buildReport().Failed > 0 {
+		m.doneFilter = doneFailed
+	}
+}
+
+// updateDone handles the Done screen keys: filtering and scrolling the file
+// list, and opening the output folder
+func (m Model) updateDone(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	n := len(m.doneEntries(m.buildReport()))
+	switch keyMsg.String() {
+	case "O":
+		m.openFolder(nil)
+	case "j", "down":
+		if m.doneCursor < n-1 {
+			m.doneCursor++
+		}
+	case "k", "up":
+		if m.doneCursor > 0 {
+			m.doneCursor--
+		}
+	case "g":
+		m.doneCursor = 0
+	case "G":
+		m.doneCursor = max(n-1, 0)
+	case "f":
+		m.doneFilter, m.doneCursor = doneFailed, 0
+	case "s":
+		m.doneFilter, m.doneCursor = doneSkipped, 0
+	case "a":
+		m.doneFilter, m.doneCursor = doneAll, 0
+	}
+	return m, nil
+}
+
+// failureBreakdown counts the report's failures by category, most common
+// first, e.g. " (2 quota, 1 not found)"
+func failureBreakdown(report Report) string {
+	counts := make(map[drive.ErrorCategory]int)
+	for _, f := range report.Files {
+		if f.Status == "failed" {
+			counts[cmp.Or(f.Category, drive.ErrorOther)]++
+		}
+	}
+	categories := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(categories, func(a, b drive.ErrorCategory) int {
+		return cmp.Or(counts[b]-counts[a], cmp.Compare(a, b))
+	})
+	parts := make([]string, len(categories))
+	for i, c := range categories {
+		parts[i] = fmt.Sprintf("%d %s", counts[c], c)
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// quotaAdvice returns APIKeyQuotaAdvice if a download failed because the API
+// key ran out of quota, or ""
+func (m Model) quotaAdvice() string {
+	m.progressMu.Lock()
+	defer m.progressMu.Unlock()
+	for _, f := range m.downloadingFiles {
+		if m.driveClient.IsAPIKeyQuota(m.fileProgress[f.ID].Error) {
+			return "API key quota reached: " + drive.APIKeyQuotaAdvice
+		}
+	}
+	return ""
+}
+
+// doneListRows is how many files the Done screen lists at once, given
+// everything else it renders around the list
+func (m Model) doneListRows(chrome string) int {
+	if m.height == 0 {
+		return 10
+	}
+	used := lipgloss.Height(chrome) + 1 // range line
+	if !m.compact() {
+		used += lipgloss.Height(TitleStyle.Render(appTitle))
+	}
+	if m.status != "" {
+		used += lipgloss.Height(m.status)
+	}
+	if m.err != nil {
+		used++
+	}
+	return max(m.height-used, 3)
+}
+
+// renderDoneFiles draws the filtered, scrollable list of finished files and
+// the full error of the one under the cursor, sized to fit beside chrome
+func (m Model) renderDoneFiles(s *strings.Builder, report Report, chrome string) {
+	if len(report.Files) == 0 {
+		return
+	}
+
+	entries := m.doneEntries(report)
+	title := SubtitleStyle.Render(fmt.Sprintf("Files (%s): %d", m.doneFilter, len(entries))) + "\n"
+	s.WriteString(title)
+	if len(entries) == 0 {
+		s.WriteString(DimStyle.Render("  None"))
+		s.WriteString("\n")
+		return
+	}
+
+	cursor := min(m.doneCursor, len(entries)-1)
+	var detail string
+	if selected := entries[cursor]; selected.Error != "" {
+		detail = "\n" + ErrorStyle.Width(max(m.width-2, 40)).Render(selected.Name+": "+selected.Error) + "\n"
+	}
+	rows := m.doneListRows(chrome + title + detail)
+	start := max(min(cursor-rows/2, len(entries)-rows), 0)
+	end := min(start+rows, len(entries))
+
+	nameWidth := max(max(m.width, 40)-16, 20)
+	for i := start; i < end; i++ {
+		f := entries[i]
+		prefix := "  "
+		if i == cursor {
+			prefix = "> "
+		}
+		status := f.Status
+		if f.Category != "" {
+			status = string(f.Category)
+		}
+		line := fmt.Sprintf("%s%-10s %s", prefix, status, truncateAndPad(f.Name, nameWidth))
+		switch {
+		case i == cursor:
+			s.WriteString(SelectedStyle.Render(line))
+		case f.Status == "failed":
+			s.WriteString(ErrorStyle.Render(line))
+		case f.Status == "done":
+			s.WriteString(NormalStyle.Render(line))
+		default:
+			s.WriteString(DimStyle.Render(line))
+		}
+		s.WriteString("\n")
+	}
+	if len(entries) > rows {
+		s.WriteString(DimStyle.Render(fmt.Sprintf("  %d-%d of %d", start+1, end, len(entries))))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(detail)
+}
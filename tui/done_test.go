@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"google-drive-dl/drive"
+)
+
+func TestDoneListFitsScreen(t *testing.T) {
+	m := Model{view: ViewDone, width: 100, height: 40, progressMu: &sync.Mutex{}, fileProgress: map[string]drive.DownloadProgress{}}
+	for i := range 100 {
+		f := drive.DriveFile{ID: fmt.Sprint(i), Name: fmt.Sprintf("file%d.txt", i)}
+		m.downloadingFiles = append(m.downloadingFiles, f)
+		m.fileProgress[f.ID] = drive.DownloadProgress{FileID: f.ID, Done: true, Error: errors.New("boom")}
+	}
+	m.doneFilter = doneFailed
+
+	for _, height := range []int{30, 40, 60} {
+		m.height = height
+		if got := strings.Count(m.View(), "\n") + 1; got != height {
+			t.Errorf("Done screen is %d lines tall in a %d-line terminal", got, height)
+		}
+	}
+}
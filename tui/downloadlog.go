@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google-drive-dl/drive"
+)
+
+// DownloadLog writes one JSON line per finished download so that unattended
+// runs leave an audit trail, even if the program crashes part way through.
+type DownloadLog struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// downloadLogEntry is a single line in the download log.
+type downloadLogEntry struct {
+	Time       time.Time `json:"time"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Bytes      int64     `json:"bytes"`
+	DurationMs int64     `json:"duration_ms"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// OpenDownloadLog opens (or creates) a JSON-lines log file, appending to any existing content
+func OpenDownloadLog(path string) (*DownloadLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open log file: %w", err)
+	}
+	return &DownloadLog{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Record appends an entry for a finished download. Each entry is written
+// straight to the file, so a crash still leaves a partial record.
+func (l *DownloadLog) Record(f drive.DriveFile, prog drive.DownloadProgress, elapsed time.Duration) error {
+	if l == nil {
+		return nil
+	}
+	entry := downloadLogEntry{
+		Time:       time.Now(),
+		ID:         f.ID,
+		Name:       f.DisplayName(),
+		Bytes:      prog.BytesLoaded,
+		DurationMs: elapsed.Milliseconds(),
+		Status:     progressStatus(prog),
+	}
+	if prog.Error != nil {
+		entry.Error = prog.Error.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(entry)
+}
+
+// Close closes the underlying log file
+func (l *DownloadLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// progressStatus returns a short status word for a file's final progress
+func progressStatus(prog drive.DownloadProgress) string {
+	switch {
+	case prog.Error != nil:
+		return "failed"
+	case prog.Cancelled:
+		return "cancelled"
+	case prog.Skipped:
+		return "skipped"
+	case prog.Done:
+		return "done"
+	}
+	return "pending"
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"google-drive-dl/drive"
+)
+
+// fileTypeCategories lists the breakdown categories in display order
+var fileTypeCategories = []string{"Images", "Videos", "PDFs", "Archives", "Google-native", "Other"}
+
+// archiveMimeTypes are the MIME types counted as archives
+var archiveMimeTypes = map[string]bool{
+	"application/zip":              true,
+	"application/x-zip-compressed": true,
+	"application/x-tar":            true,
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-bzip2":          true,
+	"application/x-xz":             true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/vnd.rar":          true,
+}
+
+// fileTypeCategory returns the breakdown category for a MIME type
+func fileTypeCategory(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "Images"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "Videos"
+	case mimeType == "application/pdf":
+		return "PDFs"
+	case archiveMimeTypes[mimeType]:
+		return "Archives"
+	case strings.HasPrefix(mimeType, "application/vnd.google-apps."):
+		return "Google-native"
+	}
+	return "Other"
+}
+
+// typeTally is the number and total size of files in one category
+type typeTally struct {
+	count int
+	bytes int64
+}
+
+// tallyFileTypes counts files and bytes per category
+func tallyFileTypes(files []drive.DriveFile) map[string]typeTally {
+	tallies := make(map[string]typeTally)
+	for _, f := range files {
+		cat := fileTypeCategory(f.MimeType)
+		t := tallies[cat]
+		t.count++
+		t.bytes += f.Size
+		tallies[cat] = t
+	}
+	return tallies
+}
+
+// renderTypesPopup draws the file type breakdown of all listed files
+func (m Model) renderTypesPopup() string {
+	var s strings.Builder
+
+	s.WriteString(BoxStyle.Render(TitleStyle.Render("File Types")))
+	s.WriteString("\n\n")
+
+	tallies := tallyFileTypes(m.allFiles)
+	for _, cat := range fileTypeCategories {
+		t, ok := tallies[cat]
+		if !ok {
+			continue
+		}
+		s.WriteString(fmt.Sprintf("  %s %6d files %10s\n", SelectedStyle.Render(padRight(cat, 14)), t.count, formatSize(t.bytes)))
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("Press b or Esc to close"))
+
+	return s.String()
+}
@@ -0,0 +1,73 @@
+package tui
+
+import "google-drive-dl/drive"
+
+// jumpTarget returns the first of n rows after cursor, or before it if back,
+// for which match is true
+func jumpTarget(n, cursor int, back bool, match func(i int) bool) (int, bool) {
+	step := 1
+	if back {
+		step = -1
+	}
+	for i := cursor + step; i >= 0 && i < n; i += step {
+		if match(i) {
+			return i, true
+		}
+	}
+	return cursor, false
+}
+
+// jumpStatus is the status shown when ] or [ finds nothing more to jump to
+func jumpStatus(what string, back bool) string {
+	if back {
+		return "No " + what + " above"
+	}
+	return "No " + what + " below"
+}
+
+// jumpToSelected moves the file cursor to the next (or previous) row of
+// rows that is selected, or for a folder row, has selected files
+func (m *Model) jumpToSelected(files, rows []drive.DriveFile, back bool) {
+	i, ok := jumpTarget(len(rows), m.fileCursor, back, func(i int) bool {
+		return m.rowCheckbox(files, rows[i]) != "[ ]"
+	})
+	if !ok {
+		m.status = jumpStatus("selected files", back)
+		return
+	}
+	m.fileCursor = i
+}
+
+// jumpToFailed points the Downloading view at the next (or previous) failed
+// file after the one it points at, and shows why it failed
+func (m *Model) jumpToFailed(back bool) {
+	m.progressMu.Lock()
+	failed := func(i int) bool {
+		f := m.downloadingFiles[i]
+		return m.fileProgress[f.ID].Error != nil && m.matchesQueueFilter(f)
+	}
+	from := -1
+	if back {
+		from = len(m.downloadingFiles)
+	}
+	for i, f := range m.downloadingFiles {
+		if f.ID == m.failedFocus {
+			from = i
+			break
+		}
+	}
+	i, ok := jumpTarget(len(m.downloadingFiles), from, back, failed)
+	var err error
+	if ok {
+		err = m.fileProgress[m.downloadingFiles[i].ID].Error
+	}
+	m.progressMu.Unlock()
+
+	if !ok {
+		m.status = jumpStatus("failed files", back)
+		return
+	}
+	f := m.downloadingFiles[i]
+	m.failedFocus = f.ID
+	m.status = f.DisplayName() + ": " + err.Error()
+}
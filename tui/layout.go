@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"google-drive-dl/drive"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// compactWidth and compactHeight are the terminal size below which the
+	// views drop the title, column headers, and dates to fit split panes
+	compactWidth  = 80
+	compactHeight = 20
+	// minWidth and minHeight are the smallest terminal the views are drawn
+	// in; below that only a notice is shown
+	minWidth  = 30
+	minHeight = 8
+	// compactChromeLines is the number of non-row lines in a compact file
+	// list: subtitle + margin, help, and room for a status or error
+	compactChromeLines = 5
+)
+
+// compact reports whether the terminal is small enough for the compact layout
+func (m Model) compact() bool {
+	return m.width > 0 && m.width < compactWidth || m.height > 0 && m.height < compactHeight
+}
+
+// tooSmall reports whether the terminal is below the smallest usable size
+func (m Model) tooSmall() bool {
+	return m.width > 0 && m.width < minWidth || m.height > 0 && m.height < minHeight
+}
+
+// viewTooSmall is shown instead of any view when the terminal is too small
+func (m Model) viewTooSmall() string {
+	msg := fmt.Sprintf("Terminal too small (%dx%d), resize to at least %dx%d", m.width, m.height, minWidth, minHeight)
+	return WarningStyle.Render(runewidth.Wrap(msg, max(m.width, 1)))
+}
+
+// listTopLines is the number of screen lines above the first file row: title
+// + margin, subtitle + margin, column header, and separator, or just the
+// subtitle + margin in the compact layout
+func (m Model) listTopLines() int {
+	if m.compact() {
+		return 2
+	}
+	return 6
+}
+
+// fitHelpWidth shortens a "key:action | key:action" help line to width by dropping
+// whole entries from the end, so the keys shown are never cut mid-word
+func fitHelpWidth(help string, width int) string {
+	if lipgloss.Width(help) <= width {
+		return help
+	}
+	entries := strings.Split(help, " | ")
+	for n := len(entries) - 1; n > 0; n-- {
+		if short := strings.Join(entries[:n], " | ") + " | ..."; lipgloss.Width(short) <= width {
+			return short
+		}
+	}
+	return truncateWidth(entries[0], width)
+}
+
+// fitHelp shortens a help line to the terminal width in the compact layout
+func (m Model) fitHelp(help string) string {
+	if !m.compact() {
+		return help
+	}
+	return fitHelpWidth(help, m.width)
+}
+
+// renderCompactFileList draws one line per file without the column header or
+// dates, sized to the real terminal width, with the help line cut to fit
+func (m Model) renderCompactFileList(s *strings.Builder, files []drive.DriveFile, config fileListConfig) {
+	// Reserve space for: cursor(2) + checkbox(3) + space(1) + icon(2) + space(1) + size(10)
+	nameWidth := max(m.width-2-3-1-2-1-10, 8)
+
+	visibleStart, visibleEnd := m.visibleRange(len(files))
+	for i := visibleStart; i < visibleEnd; i++ {
+		f := files[i]
+		cursor := "  "
+		if i == m.fileCursor {
+			cursor = "> "
+		}
+
+		checkbox := m.rowCheckbox(config.files, f)
+
+		existsIcon := "  "
+		switch m.fileExistsCache[f.ID] {
+		case drive.LocalComplete:
+			existsIcon = SuccessStyle.Render("■") + " "
+		case drive.LocalPartial:
+			existsIcon = WarningStyle.Render("■") + " "
+		}
+
+		line := fmt.Sprintf("%s%s %s%s %10s", cursor, checkbox, existsIcon, truncateAndPad(m.rowName(f, config.folderCounts), nameWidth), formatSize(f.Size))
+		if i == m.fileCursor {
+			s.WriteString(SelectedStyle.Render(line))
+		} else {
+			s.WriteString(NormalStyle.Render(line))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(HelpStyle.UnsetMarginTop().Render(m.fitHelp(config.helpText)))
+
+	if m.showInfoPopup && m.fileCursor < len(files) {
+		s.WriteString("\n")
+		s.WriteString(m.renderRowInfo(files[m.fileCursor], config))
+	}
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// openInFileManager opens dir in the native file manager without waiting for it to exit.
+func openInFileManager(dir string) error {
+	var name string
+	switch runtime.GOOS {
+	case "darwin":
+		name = "open"
+	case "windows":
+		name = "explorer"
+	default:
+		name = "xdg-open"
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("no file manager opener available (%s not found)", name)
+	}
+
+	cmd := exec.Command(path, dir)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("unable to open %s: %w", dir, err)
+	}
+	go cmd.Wait() // reap the child once the opener exits
+	return nil
+}
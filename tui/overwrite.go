@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"google-drive-dl/drive"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overwritePrompts queues the -confirm-overwrite questions of download
+// workers. The Downloading view shows the oldest and answers it; workers
+// wait for their answer.
+type overwritePrompts struct {
+	mu      sync.Mutex
+	pending []*overwritePrompt
+	all     bool // "yes to all" was answered
+}
+
+// overwritePrompt asks whether a changed local copy may be replaced
+type overwritePrompt struct {
+	file      drive.DriveFile
+	localSize int64
+	answer    chan bool // buffered, so answering never blocks
+}
+
+// confirm is the client's drive.OverwriteConfirmFunc: it waits for the user
+// to answer, or declines if ctx ends first
+func (p *overwritePrompts) confirm(ctx context.Context, file drive.DriveFile, localPath string) bool {
+	q := &overwritePrompt{file: file, answer: make(chan bool, 1)}
+	if info, err := os.Stat(localPath); err == nil {
+		q.localSize = info.Size()
+	}
+
+	p.mu.Lock()
+	if p.all {
+		p.mu.Unlock()
+		return true
+	}
+	p.pending = append(p.pending, q)
+	p.mu.Unlock()
+
+	select {
+	case yes := <-q.answer:
+		return yes
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.pending = slices.DeleteFunc(p.pending, func(o *overwritePrompt) bool { return o == q })
+		p.mu.Unlock()
+		return false
+	}
+}
+
+// current returns the question to show, if any
+func (p *overwritePrompts) current() (*overwritePrompt, bool) {
+	if p == nil {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) == 0 {
+		return nil, false
+	}
+	return p.pending[0], true
+}
+
+// answer replies to the current question. all also says yes to it and to
+// every question still to come.
+func (p *overwritePrompts) answer(yes, all bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if all {
+		p.all = true
+		for _, q := range p.pending {
+			q.answer <- true
+		}
+		p.pending = nil
+		return
+	}
+	if len(p.pending) > 0 {
+		p.pending[0].answer <- yes
+		p.pending = p.pending[1:]
+	}
+}
+
+// updateOverwritePrompt handles y/n/a while a question is shown. It reports
+// false for any other key, which the Downloading view handles as usual.
+func (m *Model) updateOverwritePrompt(msg tea.KeyMsg) bool {
+	q, ok := m.prompts.current()
+	if !ok {
+		return false
+	}
+	switch msg.String() {
+	case "y":
+		m.prompts.answer(true, false)
+		m.status = "Overwriting " + q.file.DisplayName()
+	case "n":
+		m.prompts.answer(false, false)
+		m.status = "Kept the local copy of " + q.file.DisplayName()
+	case "a":
+		m.prompts.answer(true, true)
+		m.status = "Overwriting every changed file"
+	default:
+		return false
+	}
+	return true
+}
+
+// renderOverwritePrompt draws the current question, if any
+func (m Model) renderOverwritePrompt(s *strings.Builder) {
+	q, ok := m.prompts.current()
+	if !ok {
+		return
+	}
+	s.WriteString(WarningStyle.Render(fmt.Sprintf("Overwrite %s? The local copy is %s, Drive's is %s.",
+		q.file.DisplayName(), formatSize(q.localSize), formatSize(q.file.Size))))
+	s.WriteString("\n")
+	s.WriteString(HelpStyle.Render("y:yes | n:no, keep it | a:yes to all"))
+	s.WriteString("\n")
+}
@@ -0,0 +1,270 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google-drive-dl/drive"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// downloadQueue holds the files waiting for a download worker. Workers pop
+// from the front; the UI may reorder or remove entries until they are popped.
+type downloadQueue struct {
+	mu      sync.Mutex
+	pending []drive.DriveFile
+}
+
+func newDownloadQueue(files []drive.DriveFile) *downloadQueue {
+	return &downloadQueue{pending: append([]drive.DriveFile(nil), files...)}
+}
+
+// pop removes and returns the next file, or false once the queue is empty
+func (q *downloadQueue) pop() (drive.DriveFile, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return drive.DriveFile{}, false
+	}
+	f := q.pending[0]
+	q.pending = q.pending[1:]
+	return f, true
+}
+
+// snapshot returns a copy of the pending files in queue order
+func (q *downloadQueue) snapshot() []drive.DriveFile {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]drive.DriveFile(nil), q.pending...)
+}
+
+// swap exchanges the places of two pending files. It returns false if either
+// has already started.
+func (q *downloadQueue) swap(a, b string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, j := q.indexOf(a), q.indexOf(b)
+	if i < 0 || j < 0 {
+		return false
+	}
+	q.pending[i], q.pending[j] = q.pending[j], q.pending[i]
+	return true
+}
+
+// remove drops the pending file id so no worker picks it up. It returns false
+// if the file has already started.
+func (q *downloadQueue) remove(id string) (drive.DriveFile, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.indexOf(id)
+	if i < 0 {
+		return drive.DriveFile{}, false
+	}
+	f := q.pending[i]
+	q.pending = append(q.pending[:i], q.pending[i+1:]...)
+	return f, true
+}
+
+func (q *downloadQueue) indexOf(id string) int {
+	for i, f := range q.pending {
+		if f.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// errCancelledByUser is the cause given when one file is cancelled from the
+// Downloading view, as opposed to the whole run
+var errCancelledByUser = errors.New("cancelled by user")
+
+// activeDownloads holds the cancel function of each file being downloaded, so
+// a single file can be stopped while the rest of the batch carries on.
+type activeDownloads struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+func newActiveDownloads() *activeDownloads {
+	return &activeDownloads{cancels: make(map[string]context.CancelCauseFunc)}
+}
+
+// add registers a started download
+func (a *activeDownloads) add(id string, cancel context.CancelCauseFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cancels[id] = cancel
+}
+
+// remove forgets a finished download
+func (a *activeDownloads) remove(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.cancels, id)
+}
+
+// has reports whether id is being downloaded
+func (a *activeDownloads) has(id string) bool {
+	if a == nil {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.cancels[id]
+	return ok
+}
+
+// cancel stops the download of id with errCancelledByUser. It returns false
+// if the file is no longer being downloaded.
+func (a *activeDownloads) cancel(id string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cancel, ok := a.cancels[id]
+	if ok {
+		cancel(errCancelledByUser)
+	}
+	return ok
+}
+
+// matchesQueueFilter reports whether f matches the Downloading view's filter
+func (m Model) matchesQueueFilter(f drive.DriveFile) bool {
+	filter := strings.ToLower(m.queueFilter.Value())
+	return filter == "" || strings.Contains(strings.ToLower(f.DisplayName()), filter)
+}
+
+// inProgress returns the files being downloaded that match the queue filter,
+// in the order they were queued
+func (m Model) inProgress() []drive.DriveFile {
+	var files []drive.DriveFile
+	for _, f := range m.downloadingFiles {
+		if m.active.has(f.ID) && m.matchesQueueFilter(f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// visibleQueue returns the pending files matching the queue filter
+func (m Model) visibleQueue() []drive.DriveFile {
+	var visible []drive.DriveFile
+	for _, f := range m.queue.snapshot() {
+		if m.matchesQueueFilter(f) {
+			visible = append(visible, f)
+		}
+	}
+	return visible
+}
+
+// updateDownloading handles the queue keys while a download is running:
+// moving the cursor over the files in progress and the queue after them,
+// reordering queued files, cancelling single files, and filtering.
+func (m Model) updateDownloading(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.downloadDone || m.cancelling {
+		return m, nil
+	}
+	if m.updateOverwritePrompt(keyMsg) {
+		return m, nil
+	}
+
+	if m.queueFilter.Focused() {
+		switch keyMsg.String() {
+		case "enter":
+			m.queueFilter.Blur()
+		case "esc":
+			m.queueFilter.Blur()
+			m.queueFilter.SetValue("")
+		default:
+			var cmd tea.Cmd
+			m.queueFilter, cmd = m.queueFilter.Update(msg)
+			m.queueCursor = 0
+			return m, cmd
+		}
+		m.queueCursor = 0
+		return m, nil
+	}
+
+	// The cursor runs over the files in progress, then the queue
+	active, visible := m.inProgress(), m.visibleQueue()
+	rows := len(active) + len(visible)
+	if m.queueCursor >= rows {
+		m.queueCursor = max(rows-1, 0)
+	}
+	qi := m.queueCursor - len(active) // cursor position within the queue
+
+	if key := keyMsg.String(); key == "]" || key == "[" {
+		m.jumpToFailed(key == "[")
+		return m, nil
+	}
+	// Any other key returns the cursor from a failed file to the queue
+	m.failedFocus = ""
+
+	switch keyMsg.String() {
+	case "j", "down":
+		if m.queueCursor < rows-1 {
+			m.queueCursor++
+		}
+	case "k", "up":
+		if m.queueCursor > 0 {
+			m.queueCursor--
+		}
+	case "J", "shift+down":
+		// Swapping with the next visible file keeps moves sensible while filtered
+		if qi >= 0 && qi < len(visible)-1 && m.queue.swap(visible[qi].ID, visible[qi+1].ID) {
+			m.queueCursor++
+		}
+	case "K", "shift+up":
+		if qi > 0 && qi < len(visible) && m.queue.swap(visible[qi].ID, visible[qi-1].ID) {
+			m.queueCursor--
+		}
+	case "d", "delete":
+		if rows == 0 {
+			return m, nil
+		}
+		if qi < 0 {
+			// The worker settles the file as cancelled and moves on to the queue
+			f := active[m.queueCursor]
+			if m.active.cancel(f.ID) {
+				m.status = "Cancelling " + f.DisplayName()
+			} else {
+				m.status = "Already finished"
+			}
+			return m, nil
+		}
+		f, ok := m.queue.remove(visible[qi].ID)
+		if !ok {
+			m.status = "Already started"
+			return m, nil
+		}
+		// Settle the file as cancelled so the totals and report still add up
+		m.progressMu.Lock()
+		m.fileProgress[f.ID] = drive.DownloadProgress{
+			FileID:    f.ID,
+			FileName:  f.DisplayName(),
+			Done:      true,
+			Cancelled: true,
+		}
+		m.batch.Update(m.fileProgress[f.ID])
+		m.progressMu.Unlock()
+		m.status = "Removed " + f.DisplayName() + " from the queue"
+	case "+", "=":
+		m.maxConcurrent = m.pool.resize(m.maxConcurrent + 1)
+		m.status = fmt.Sprintf("Up to %d downloads at a time", m.maxConcurrent)
+	case "-", "_":
+		m.maxConcurrent = m.pool.resize(m.maxConcurrent - 1)
+		m.status = fmt.Sprintf("Up to %d downloads at a time; running ones finish first", m.maxConcurrent)
+	case "/":
+		m.queueFilter.Focus()
+		return m, textinput.Blink
+	}
+
+	return m, nil
+}
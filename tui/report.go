@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"google-drive-dl/drive"
+)
+
+// Report summarizes a finished download run for scripts and automation.
+type Report struct {
+	Total     int          `json:"total"`
+	Succeeded int          `json:"succeeded"`
+	Skipped   int          `json:"skipped"`
+	Failed    int          `json:"failed"`
+	Cancelled int          `json:"cancelled"`
+	Files     []ReportFile `json:"files"`
+	// DeletedUpstream lists files removed from Drive since the last -sync run
+	DeletedUpstream []string `json:"deleted_upstream,omitempty"`
+	// TimedOut is set when the run's time limit stopped it
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// ReportFile is the outcome of a single file in a Report.
+type ReportFile struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Bytes  int64  `json:"bytes"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Category groups failures by cause (see drive.CategorizeError)
+	Category drive.ErrorCategory `json:"category,omitempty"`
+	// Where the file was listed, so -retry-report can save it as the first
+	// attempt would have: fetching it again by ID doesn't tell
+	Path           string `json:"path,omitempty"`
+	FolderID       string `json:"folder_id,omitempty"`
+	RootFolderID   string `json:"root_folder_id,omitempty"`
+	RootFolderName string `json:"root_folder_name,omitempty"`
+}
+
+// newReportFile starts the report entry of f with the given status
+func newReportFile(f drive.DriveFile, status string) ReportFile {
+	return ReportFile{
+		ID:             f.ID,
+		Name:           f.DisplayName(),
+		Status:         status,
+		Path:           f.Path,
+		FolderID:       f.FolderID,
+		RootFolderID:   f.RootFolderID,
+		RootFolderName: f.RootFolderName,
+	}
+}
+
+// Restore returns file, fetched again by ID, with the listing fields the
+// report kept for it. Reports written before they were kept only give the
+// folder path, as part of the name.
+func (rf ReportFile) Restore(file drive.DriveFile) drive.DriveFile {
+	if rf.FolderID == "" && rf.RootFolderID == "" && rf.Path == "" {
+		if folder, ok := strings.CutSuffix(rf.Name, "/"+file.Name); ok {
+			file.Path = folder
+		} else if i := strings.LastIndexByte(rf.Name, '/'); i >= 0 {
+			file.Path = rf.Name[:i]
+		}
+		return file
+	}
+	file.Path = rf.Path
+	file.FolderID = cmp.Or(rf.FolderID, file.FolderID)
+	file.RootFolderID = rf.RootFolderID
+	file.RootFolderName = rf.RootFolderName
+	return file
+}
+
+// buildReport collects the final state of every file in the download queue.
+// Files that never started because the run was cancelled count as cancelled.
+func (m Model) buildReport() Report {
+	r := Report{Total: len(m.downloadingFiles), Files: []ReportFile{}, TimedOut: m.timedOut}
+
+	m.progressMu.Lock()
+	defer m.progressMu.Unlock()
+
+	for _, f := range m.downloadingFiles {
+		entry := newReportFile(f, "cancelled")
+		if prog, ok := m.fileProgress[f.ID]; ok {
+			entry.Bytes = prog.BytesLoaded
+			entry.Status = progressStatus(prog)
+			if prog.Error != nil {
+				entry.Error = prog.Error.Error()
+				entry.Category = drive.CategorizeError(prog.Error)
+			}
+		}
+
+		switch entry.Status {
+		case "done":
+			r.Succeeded++
+		case "skipped":
+			r.Skipped++
+		case "failed":
+			r.Failed++
+		case "cancelled":
+			r.Cancelled++
+		}
+		r.Files = append(r.Files, entry)
+	}
+
+	for _, cf := range m.deletedUpstream {
+		name := cf.Name
+		if cf.Path != "" {
+			name = cf.Path + "/" + cf.Name
+		}
+		r.DeletedUpstream = append(r.DeletedUpstream, name)
+	}
+
+	return r
+}
+
+// HasFailures reports whether any file in the last download run failed, or an
+// automatic download was refused because the selection exceeded a cap, or the
+// run timed out.
+func (m Model) HasFailures() bool {
+	return m.capExceeded || m.timedOut || m.buildReport().Failed > 0
+}
+
+// ReadReport reads a report written by an earlier run.
+func ReadReport(path string) (Report, error) {
+	var r Report
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, fmt.Errorf("unable to read report: %w", err)
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("unable to parse report %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// FailedFiles returns the files whose download failed.
+func (r Report) FailedFiles() []ReportFile {
+	var failed []ReportFile
+	for _, f := range r.Files {
+		if f.Status == "failed" {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
+
+// writeReport writes the run summary to the configured report path, if any
+func (m Model) writeReport() error {
+	if m.reportPath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(m.buildReport(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode report: %w", err)
+	}
+	if err := os.WriteFile(m.reportPath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("unable to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package tui
+
+import (
+	"testing"
+
+	"google-drive-dl/drive"
+)
+
+func TestReportFileRestore(t *testing.T) {
+	listed := drive.DriveFile{ID: "f1", Name: "a.jpg", Path: "Trips/2023", FolderID: "sub", RootFolderID: "root", RootFolderName: "Photos"}
+	fetched := drive.DriveFile{ID: "f1", Name: "a.jpg", FolderID: "sub"}
+
+	if got := newReportFile(listed, "failed").Restore(fetched); got != listed {
+		t.Errorf("restored %+v, want %+v", got, listed)
+	}
+
+	// Older reports only have the display name
+	old := ReportFile{ID: "f1", Name: "Trips/2023/a.jpg", Status: "failed"}
+	if got := old.Restore(fetched); got.Path != "Trips/2023" {
+		t.Errorf("restored path %q from an old report, want Trips/2023", got.Path)
+	}
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google-drive-dl/cache"
+	"google-drive-dl/drive"
+)
+
+// batchKey identifies a batch for resuming: the folders it was listed from,
+// and the directory it downloads into. Files from a report, and revisions,
+// which would otherwise share the folders' record, go by their own IDs.
+// It is empty when there are neither.
+func (m Model) batchKey(files []drive.DriveFile) string {
+	_, key := folderKey(m.links)
+	if key == "" || slices.ContainsFunc(files, func(f drive.DriveFile) bool { return f.RevisionID != "" }) {
+		ids := make([]string, len(files))
+		for i, f := range files {
+			ids[i] = f.ID
+		}
+		slices.Sort(ids)
+		key = strings.Join(ids, ",")
+	}
+	if key == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(m.outputDir())
+	if err != nil {
+		dir = m.outputDir()
+	}
+	return key + "|" + dir
+}
+
+// resumeBatch leaves out of files those an earlier, unfinished run of the
+// same batch already finished, and returns how many it left out. A file is
+// only left out if it hasn't changed on Drive since and its local copy is
+// still there. If that would leave nothing, the batch starts over with
+// every file. -force always downloads every file.
+func (m Model) resumeBatch(files []drive.DriveFile) ([]drive.DriveFile, int) {
+	if m.cacheManager == nil || m.batchID == "" || m.forceDownload {
+		return files, 0
+	}
+	done, err := m.cacheManager.CompletedFiles(m.batchID)
+	if err != nil {
+		slog.Warn("unable to read the batch state, downloading every file", "error", err)
+		return files, 0
+	}
+
+	var rest []drive.DriveFile
+	for _, f := range files {
+		if cf, ok := done[f.ID]; !ok || !m.stillComplete(f, cf) {
+			rest = append(rest, f)
+		}
+	}
+	if len(rest) == 0 {
+		m.forgetBatch()
+		return files, 0
+	}
+	return rest, len(files) - len(rest)
+}
+
+// stillComplete reports whether f, finished by an earlier run as cf, is
+// unchanged on Drive and still saved locally
+func (m Model) stillComplete(f drive.DriveFile, cf cache.CompletedFile) bool {
+	if !f.ModifiedTime.Equal(cf.ModifiedTime) || f.Size != cf.Size {
+		return false
+	}
+	path, err := m.driveClient.DestPath(m.outputDir(), f)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// completeFile records that f finished, as Drive listed it
+func (m Model) completeFile(f drive.DriveFile) {
+	if err := m.batchState.Complete(cache.CompletedFile{ID: f.ID, ModifiedTime: f.ModifiedTime, Size: f.Size}); err != nil {
+		slog.Warn("unable to record the batch state", "file", f.DisplayName(), "error", err)
+	}
+}
+
+// openBatchState starts recording the files the batch finishes
+func (m *Model) openBatchState() {
+	m.batchState = nil
+	if m.cacheManager == nil || m.batchID == "" {
+		return
+	}
+	state, err := m.cacheManager.OpenBatchState(m.batchID)
+	if err != nil {
+		slog.Warn("unable to record the batch state, it can't be resumed", "error", err)
+		return
+	}
+	m.batchState = state
+}
+
+// finishBatchState stops recording, and forgets the batch once every file in
+// it finished, so the next run starts afresh
+func (m *Model) finishBatchState(p drive.BatchProgress) {
+	if m.batchState == nil {
+		return
+	}
+	m.batchState.Close()
+	m.batchState = nil
+	if p.FilesDone == p.FilesTotal && p.FilesFailed == 0 && p.FilesCancelled == 0 {
+		m.forgetBatch()
+	}
+}
+
+// forgetBatch removes the record of the current batch
+func (m Model) forgetBatch() {
+	if err := m.cacheManager.ForgetBatch(m.batchID); err != nil {
+		slog.Warn("unable to remove the batch state", "error", err)
+	}
+}
+
+// resumeStatus describes the files resumeBatch left out
+func resumeStatus(skipped int) string {
+	if skipped == 1 {
+		return "Resuming: 1 file was finished by an earlier run"
+	}
+	return fmt.Sprintf("Resuming: %d files were finished by an earlier run", skipped)
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"google-drive-dl/cache"
+	"google-drive-dl/drive"
+)
+
+func TestResumeBatch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	manager, err := cache.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	then := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	files := []drive.DriveFile{
+		{ID: "saved", Name: "saved.txt", Size: 2, ModifiedTime: then},
+		{ID: "deleted", Name: "deleted.txt", Size: 2, ModifiedTime: then},
+		{ID: "edited", Name: "edited.txt", Size: 2, ModifiedTime: then.Add(time.Hour)},
+		{ID: "new", Name: "new.txt", Size: 2, ModifiedTime: then},
+	}
+	for _, name := range []string{"saved.txt", "edited.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("ok"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The folders gained "new" since the interrupted run
+	m := Model{cacheManager: manager, destDir: dir, links: []string{"https://drive.google.com/drive/folders/folder1"}}
+	m.batchID = m.batchKey(files[:3])
+	m.openBatchState()
+	for _, f := range files[:2] {
+		m.completeFile(f)
+	}
+	// Finished before it was edited on Drive
+	m.completeFile(drive.DriveFile{ID: "edited", Size: 2, ModifiedTime: then})
+	m.batchState.Close()
+
+	ids := func(files []drive.DriveFile) []string {
+		var ids []string
+		for _, f := range files {
+			ids = append(ids, f.ID)
+		}
+		return ids
+	}
+	if m.batchKey(files) != m.batchID {
+		t.Errorf("batchKey() changed when a file was added to the folders")
+	}
+	rest, skipped := m.resumeBatch(files)
+	if want := []string{"deleted", "edited", "new"}; !slices.Equal(ids(rest), want) || skipped != 1 {
+		t.Errorf("resumeBatch() = %v, %d; want %v, 1", ids(rest), skipped, want)
+	}
+
+	// Other folders, or files from a report, into the same directory are
+	// different batches
+	for _, links := range [][]string{{"https://drive.google.com/drive/folders/folder2"}, nil} {
+		other := Model{cacheManager: manager, destDir: dir, links: links}
+		other.batchID = other.batchKey(files[:1])
+		if rest, skipped := other.resumeBatch(files[:1]); len(rest) != 1 || skipped != 0 {
+			t.Errorf("links %v: resumeBatch() of another batch left out %d files", links, skipped)
+		}
+	}
+
+	// With nothing left, the batch starts over
+	m.links = nil
+	m.batchID = m.batchKey(files[:1])
+	m.openBatchState()
+	m.completeFile(files[0])
+	m.batchState.Close()
+	if rest, skipped := m.resumeBatch(files[:1]); len(rest) != 1 || skipped != 0 {
+		t.Errorf("resumeBatch() of a finished batch = %v, %d; want every file", ids(rest), skipped)
+	}
+	if done, _ := manager.CompletedFiles(m.batchID); len(done) != 0 {
+		t.Errorf("finished batch still recorded: %v", done)
+	}
+}
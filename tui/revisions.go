@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google-drive-dl/drive"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// revisionsShown is how many revisions the info popup lists at once
+const revisionsShown = 8
+
+// revisionsLoadedMsg carries the revisions of the file in the info popup
+type revisionsLoadedMsg struct {
+	fileID    string
+	revisions []drive.Revision
+	err       error
+}
+
+// revisionPicker is the revision list of the info popup. The cursor starts
+// on the last revision, the current content.
+type revisionPicker struct {
+	fileID    string
+	revisions []drive.Revision
+	err       error
+	loading   bool
+	cursor    int
+}
+
+// openInfo shows the info popup for row and, for a file, starts loading its
+// revisions
+func (m *Model) openInfo(row drive.DriveFile) tea.Cmd {
+	m.showInfoPopup = true
+	m.revisions = revisionPicker{fileID: row.ID}
+	if isFolderRow(row) || m.offline || m.driveClient == nil {
+		return nil
+	}
+	m.revisions.loading = true
+	client, ctx := m.driveClient, m.ctx
+	return func() tea.Msg {
+		revisions, err := client.ListRevisions(ctx, row)
+		return revisionsLoadedMsg{fileID: row.ID, revisions: revisions, err: err}
+	}
+}
+
+// revisionsLoaded records msg if it is for the file still in the popup
+func (m *Model) revisionsLoaded(msg revisionsLoadedMsg) {
+	if msg.fileID != m.revisions.fileID {
+		return
+	}
+	m.revisions.loading = false
+	m.revisions.revisions = msg.revisions
+	m.revisions.err = msg.err
+	m.revisions.cursor = max(len(msg.revisions)-1, 0)
+}
+
+// updateInfoPopup handles keys while the info popup for row is open: j/k
+// pick a revision and Enter downloads it. Other keys are ignored.
+func (m Model) updateInfoPopup(msg tea.KeyMsg, row drive.DriveFile) (tea.Model, tea.Cmd) {
+	revs := &m.revisions
+	switch msg.String() {
+	case "i", "esc":
+		m.showInfoPopup = false
+	case "j", "down":
+		if revs.cursor < len(revs.revisions)-1 {
+			revs.cursor++
+		}
+	case "k", "up":
+		if revs.cursor > 0 {
+			revs.cursor--
+		}
+	case "enter":
+		if revs.fileID != row.ID || revs.cursor >= len(revs.revisions) {
+			return m, nil
+		}
+		m.showInfoPopup = false
+		return m.downloadBatch([]drive.DriveFile{row.AtRevision(revs.revisions[revs.cursor])})
+	}
+	return m, nil
+}
+
+// renderRevisions adds the revision list to the info popup of f
+func (m Model) renderRevisions(s *strings.Builder, f drive.DriveFile) {
+	revs := m.revisions
+	if revs.fileID != f.ID || (!revs.loading && revs.err == nil && len(revs.revisions) == 0) {
+		return
+	}
+	label := SelectedStyle.Render("Revisions")
+	switch {
+	case revs.loading:
+		s.WriteString(fmt.Sprintf("  %s: %s\n", label, DimStyle.Render("loading...")))
+		return
+	case errors.Is(revs.err, drive.ErrNoRevisions):
+		s.WriteString(fmt.Sprintf("  %s: %s\n", label, DimStyle.Render("not available, only the current version can be downloaded")))
+		return
+	case revs.err != nil:
+		s.WriteString(fmt.Sprintf("  %s: %s\n", label, ErrorStyle.Render(revs.err.Error())))
+		return
+	}
+
+	s.WriteString(fmt.Sprintf("  %s: %d\n", label, len(revs.revisions)))
+	start := min(max(revs.cursor-revisionsShown/2, 0), max(len(revs.revisions)-revisionsShown, 0))
+	end := min(start+revisionsShown, len(revs.revisions))
+	for i := start; i < end; i++ {
+		r := revs.revisions[i]
+		modified := "????-??-?? ??:??"
+		if !r.ModifiedTime.IsZero() {
+			modified = r.ModifiedTime.Format("2006-01-02 15:04")
+		}
+		line := fmt.Sprintf("%3d  %s  %10s  %s", i+1, modified, formatSize(r.Size), cmp.Or(r.ModifiedBy, "-"))
+		if i == len(revs.revisions)-1 {
+			line += " (current)"
+		}
+		if i == revs.cursor {
+			s.WriteString("    " + SelectedStyle.Render("> "+line) + "\n")
+		} else {
+			s.WriteString("      " + line + "\n")
+		}
+	}
+}
+
+// infoPopupHelp returns the help line of the info popup
+func (m Model) infoPopupHelp(f drive.DriveFile) string {
+	if m.revisions.fileID == f.ID && len(m.revisions.revisions) > 0 {
+		return "j/k:pick revision | Enter:download it | i/Esc:close"
+	}
+	return "Press i or Esc to close"
+}
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"google-drive-dl/cache"
+	"google-drive-dl/drive"
+)
+
+// folderKey returns the sorted folder IDs in links and the key that
+// identifies that set of folders in the cache
+func folderKey(links []string) ([]string, string) {
+	var folderIDs []string
+	for _, link := range links {
+		folderID, err := drive.ExtractFolderID(link)
+		if err != nil {
+			continue
+		}
+		folderIDs = append(folderIDs, folderID)
+	}
+
+	// Sort for consistent cache key
+	sort.Strings(folderIDs)
+	return folderIDs, strings.Join(folderIDs, "+")
+}
+
+// saveSelection stores the selected files for the current folders so a later
+// run can restore them. With nothing selected it forgets the saved selection.
+func (m *Model) saveSelection() {
+	if m.cacheManager == nil {
+		m.err = fmt.Errorf("unable to save selection: cache directory unavailable")
+		return
+	}
+
+	var ids []string
+	for id, selected := range m.selectedFiles {
+		if selected {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	_, key := folderKey(m.links)
+	if err := m.cacheManager.SaveSelection(key, ids); err != nil {
+		m.err = fmt.Errorf("unable to save selection: %w", err)
+		return
+	}
+	m.err = nil
+	if len(ids) == 0 {
+		m.status = "Cleared the saved selection"
+	} else {
+		m.status = fmt.Sprintf("Saved selection of %d files", len(ids))
+	}
+}
+
+// offerSavedSelection looks for a selection saved for the current folders
+// and, if any of its files are listed, asks whether to restore it. It asks
+// at most once per run and never over a selection already being made.
+func (m *Model) offerSavedSelection() {
+	if m.selectionOffered || m.cacheManager == nil {
+		return
+	}
+	m.selectionOffered = true
+	for _, selected := range m.selectedFiles {
+		if selected {
+			return
+		}
+	}
+
+	_, key := folderKey(m.links)
+	saved := m.cacheManager.GetSelection(key)
+	if saved == nil || m.countListed(saved) == 0 {
+		return
+	}
+	m.savedSelection = saved
+}
+
+// countListed returns how many of a saved selection's files are in allFiles
+func (m Model) countListed(saved *cache.Selection) int {
+	listed := make(map[string]bool, len(m.allFiles))
+	for _, f := range m.allFiles {
+		listed[f.ID] = true
+	}
+	n := 0
+	for _, id := range saved.FileIDs {
+		if listed[id] {
+			n++
+		}
+	}
+	return n
+}
+
+// answerRestorePrompt restores the offered selection when restore is true
+// and dismisses the prompt either way. Files no longer listed are ignored.
+func (m *Model) answerRestorePrompt(restore bool) {
+	saved := m.savedSelection
+	m.savedSelection = nil
+	if !restore {
+		return
+	}
+
+	listed := make(map[string]bool, len(m.allFiles))
+	for _, f := range m.allFiles {
+		listed[f.ID] = true
+	}
+	restored := 0
+	for _, id := range saved.FileIDs {
+		if listed[id] {
+			m.selectedFiles[id] = true
+			restored++
+		}
+	}
+	m.status = fmt.Sprintf("Restored selection of %d files", restored)
+	if missing := len(saved.FileIDs) - restored; missing > 0 {
+		m.status += fmt.Sprintf(" (%d no longer listed)", missing)
+	}
+}
+
+// renderRestorePrompt draws the question asked by offerSavedSelection
+func (m Model) renderRestorePrompt(s *strings.Builder) {
+	if m.savedSelection == nil {
+		return
+	}
+	s.WriteString(WarningStyle.Render(fmt.Sprintf("Restore the selection of %d files saved %s? (y/n)",
+		m.countListed(m.savedSelection), formatTimeAgo(m.savedSelection.SavedAt))))
+	s.WriteString("\n")
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google-drive-dl/drive"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// modifiedSince drops files modified before m.since, including files with no
+// modified time, when a since filter is set
+func (m Model) modifiedSince(files []drive.DriveFile) []drive.DriveFile {
+	return drive.FilterModifiedSince(files, m.since)
+}
+
+// listFilters applies the filters that narrow every list: the -glob patterns,
+// the MIME type lists and the modified-since filter
+func (m Model) listFilters(files []drive.DriveFile) []drive.DriveFile {
+	return m.mimes.Filter(drive.FilterFilesByGlob(m.modifiedSince(files), m.globs))
+}
+
+// globIndicator notes the -glob patterns for list headers
+func (m Model) globIndicator() string {
+	if len(m.globs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(m.globs, ", ") + "]"
+}
+
+// sinceIndicator notes the active modified-since filter for list headers
+func (m Model) sinceIndicator() string {
+	if m.since.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" [modified since %s]", m.since.Format("2006-01-02 15:04"))
+}
+
+// editSince opens the modified-since prompt, pre-filled with the current filter
+func (m Model) editSince() (tea.Model, tea.Cmd) {
+	m.sinceInput.SetValue("")
+	if !m.since.IsZero() {
+		m.sinceInput.SetValue(m.since.Format(time.DateOnly))
+	}
+	m.sinceInput.CursorEnd()
+	m.sinceInput.Focus()
+	return m, textinput.Blink
+}
+
+// updateSince handles keys while the modified-since prompt is open. Enter
+// applies the typed date or duration (empty clears the filter), Esc cancels.
+func (m Model) updateSince(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		value := strings.TrimSpace(m.sinceInput.Value())
+		if value == "" {
+			m.since = time.Time{}
+			m.status = "Showing files from any date"
+		} else {
+			since, err := drive.ParseSince(value, time.Now())
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.since = since
+			m.status = "Showing files modified since " + since.Format("2006-01-02 15:04")
+		}
+		m.err = nil
+		m.sinceInput.Blur()
+		m.refreshDerivedLists()
+		return m, nil
+	case "esc":
+		m.sinceInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.sinceInput, cmd = m.sinceInput.Update(msg)
+	return m, cmd
+}
+
+// listHelp returns the help line for a file list, replaced by the
+// modified-since prompt while it is open
+func (m Model) listHelp(help string) string {
+	if m.sinceInput.Focused() {
+		return m.sinceInput.View() + "  (date or duration, empty clears | Enter:apply | Esc:cancel)"
+	}
+	return help
+}
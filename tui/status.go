@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google-drive-dl/drive"
+)
+
+// statusShutdownTimeout is how long stopping the status server waits for
+// requests in flight
+const statusShutdownTimeout = time.Second
+
+// Status is the progress of the running batch, served as JSON at /status
+// when Options.StatusAddr is set.
+type Status struct {
+	FilesTotal     int     `json:"files_total"`
+	FilesDone      int     `json:"files_done"`
+	FilesFailed    int     `json:"files_failed"`
+	FilesSkipped   int     `json:"files_skipped"`
+	FilesCancelled int     `json:"files_cancelled"`
+	TotalBytes     int64   `json:"total_bytes"`
+	LoadedBytes    int64   `json:"loaded_bytes"`
+	Percent        float64 `json:"percent"`
+	// Files holds every file of the batch. Besides the statuses of a Report,
+	// a file can be queued, downloading, or retrying.
+	Files []ReportFile `json:"files"`
+}
+
+// buildStatus snapshots the progress of the batch being downloaded
+func (m Model) buildStatus() Status {
+	m.progressMu.Lock()
+	defer m.progressMu.Unlock()
+
+	p := m.batch.Progress()
+	s := Status{
+		FilesTotal:     p.FilesTotal,
+		FilesDone:      p.FilesDone,
+		FilesFailed:    p.FilesFailed,
+		FilesSkipped:   p.FilesSkipped,
+		FilesCancelled: p.FilesCancelled,
+		TotalBytes:     p.TotalBytes,
+		LoadedBytes:    p.LoadedBytes,
+		Percent:        p.Percent(),
+		Files:          make([]ReportFile, 0, len(m.downloadingFiles)),
+	}
+	for _, f := range m.downloadingFiles {
+		entry := newReportFile(f, "queued")
+		if prog, ok := m.fileProgress[f.ID]; ok {
+			entry.Bytes = prog.BytesLoaded
+			entry.Status = liveStatus(prog)
+			if prog.Error != nil {
+				entry.Error = prog.Error.Error()
+				entry.Category = drive.CategorizeError(prog.Error)
+			}
+		}
+		s.Files = append(s.Files, entry)
+	}
+	return s
+}
+
+// liveStatus is progressStatus for a file that may still be downloading
+func liveStatus(prog drive.DownloadProgress) string {
+	switch {
+	case prog.Done || prog.Error != nil || prog.Cancelled || prog.Skipped:
+		return progressStatus(prog)
+	case prog.Retry > 0:
+		return "retrying"
+	}
+	return "downloading"
+}
+
+// serveStatus serves buildStatus at /status on ln. It is called once the
+// batch's state is set up, since the handler keeps this copy of the model.
+func (m Model) serveStatus(ln net.Listener) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.buildStatus())
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("status server stopped", "err", err)
+		}
+	}()
+	return srv
+}
+
+// closeListener closes ln, if it was opened
+func closeListener(ln net.Listener) {
+	if ln != nil {
+		ln.Close()
+	}
+}
+
+// stopStatusServer shuts the status server down, if one is running
+func (m *Model) stopStatusServer() {
+	if m.statusServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), statusShutdownTimeout)
+	defer cancel()
+	if err := m.statusServer.Shutdown(ctx); err != nil {
+		slog.Warn("status server did not shut down cleanly", "err", err)
+	}
+	m.statusServer = nil
+}
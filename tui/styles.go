@@ -1,56 +1,134 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the color palette the TUI styles are derived from.
+type Theme struct {
+	// Primary is used for titles, the cursor row, and labels
+	Primary lipgloss.TerminalColor
+	// Secondary is used for dimmed text, help lines, and borders
+	Secondary lipgloss.TerminalColor
+	// Text is the default foreground for list rows
+	Text lipgloss.TerminalColor
+	// Success is used for completed items and progress bars
+	Success lipgloss.TerminalColor
+	// Error is used for failures
+	Error lipgloss.TerminalColor
+	// Warning is used for warnings and in-progress notices
+	Warning lipgloss.TerminalColor
+}
 
 var (
-	// Colors
-	primaryColor   = lipgloss.Color("39")  // Blue
-	secondaryColor = lipgloss.Color("245") // Gray
-	successColor   = lipgloss.Color("42")  // Green
-	errorColor     = lipgloss.Color("196") // Red
-	warningColor   = lipgloss.Color("214") // Orange
+	// DarkTheme is the default palette, tuned for dark terminal backgrounds.
+	DarkTheme = Theme{
+		Primary:   lipgloss.Color("39"),  // Blue
+		Secondary: lipgloss.Color("245"), // Gray
+		Text:      lipgloss.Color("252"), // Light gray
+		Success:   lipgloss.Color("42"),  // Green
+		Error:     lipgloss.Color("196"), // Red
+		Warning:   lipgloss.Color("214"), // Orange
+	}
+
+	// LightTheme uses darker shades that stay readable on light backgrounds.
+	LightTheme = Theme{
+		Primary:   lipgloss.Color("25"),  // Dark blue
+		Secondary: lipgloss.Color("243"), // Gray
+		Text:      lipgloss.Color("235"), // Near black
+		Success:   lipgloss.Color("28"),  // Dark green
+		Error:     lipgloss.Color("160"), // Dark red
+		Warning:   lipgloss.Color("130"), // Brown/orange
+	}
+
+	// MonoTheme sets no colors at all. It is used for NO_COLOR and redirected output.
+	MonoTheme = Theme{
+		Primary:   lipgloss.NoColor{},
+		Secondary: lipgloss.NoColor{},
+		Text:      lipgloss.NoColor{},
+		Success:   lipgloss.NoColor{},
+		Error:     lipgloss.NoColor{},
+		Warning:   lipgloss.NoColor{},
+	}
+)
 
+// ThemeByName returns the theme registered under name.
+func ThemeByName(name string) (Theme, error) {
+	switch name {
+	case "", "dark":
+		return DarkTheme, nil
+	case "light":
+		return LightTheme, nil
+	case "mono":
+		return MonoTheme, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (valid: dark, light, mono)", name)
+}
+
+var (
 	// Styles
+	TitleStyle       lipgloss.Style
+	SubtitleStyle    lipgloss.Style
+	SelectedStyle    lipgloss.Style
+	NormalStyle      lipgloss.Style
+	DimStyle         lipgloss.Style
+	ErrorStyle       lipgloss.Style
+	SuccessStyle     lipgloss.Style
+	WarningStyle     lipgloss.Style
+	HelpStyle        lipgloss.Style
+	BoxStyle         lipgloss.Style
+	ProgressBarFull  lipgloss.Style
+	ProgressBarEmpty lipgloss.Style
+)
+
+func init() {
+	ApplyTheme(DarkTheme)
+}
+
+// ApplyTheme rebuilds all styles from the given theme. Call it before starting the program.
+func ApplyTheme(t Theme) {
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(t.Primary).
+		MarginBottom(1)
 
 	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			MarginBottom(1)
+		Foreground(t.Secondary).
+		MarginBottom(1)
 
 	SelectedStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+		Foreground(t.Primary).
+		Bold(true)
 
 	NormalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+		Foreground(t.Text)
 
 	DimStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor)
+		Foreground(t.Secondary)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(errorColor)
+		Foreground(t.Error)
 
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(successColor)
+		Foreground(t.Success)
 
 	WarningStyle = lipgloss.NewStyle().
-			Foreground(warningColor)
+		Foreground(t.Warning)
 
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			MarginTop(1)
+		Foreground(t.Secondary).
+		MarginTop(1)
 
 	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(secondaryColor).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Secondary).
+		Padding(0, 1)
 
 	ProgressBarFull = lipgloss.NewStyle().
-			Foreground(successColor)
+		Foreground(t.Success)
 
 	ProgressBarEmpty = lipgloss.NewStyle().
-				Foreground(secondaryColor)
-)
+		Foreground(t.Secondary)
+}
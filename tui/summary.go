@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"google-drive-dl/drive"
+
+	"github.com/atotto/clipboard"
+)
+
+// selectionSummary describes the selected files among files for sharing: a
+// line with their count and total size, then their names, one per line. It
+// also returns the count.
+func (m Model) selectionSummary(files []drive.DriveFile) (string, int) {
+	var names []string
+	var total int64
+	for _, f := range files {
+		if m.selectedFiles[f.ID] {
+			names = append(names, f.DisplayName())
+			total += f.Size
+		}
+	}
+
+	noun := "files"
+	if len(names) == 1 {
+		noun = "file"
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "%d %s, %s\n", len(names), noun, formatSize(total))
+	for _, name := range names {
+		s.WriteString(name + "\n")
+	}
+	return s.String(), len(names)
+}
+
+// copySelectionSummary copies the summary of the selected files to the
+// clipboard, or writes it to the summary file when one was given
+func (m *Model) copySelectionSummary(files []drive.DriveFile) {
+	summary, n := m.selectionSummary(files)
+	if n == 0 {
+		m.err = fmt.Errorf("no files selected")
+		return
+	}
+
+	if m.summaryPath != "" {
+		if err := os.WriteFile(m.summaryPath, []byte(summary), 0o644); err != nil {
+			m.err = fmt.Errorf("unable to write summary: %w", err)
+			return
+		}
+		m.err = nil
+		m.status = fmt.Sprintf("Wrote a summary of %d files to %s", n, m.summaryPath)
+		return
+	}
+	if err := clipboard.WriteAll(summary); err != nil {
+		m.err = fmt.Errorf("unable to copy to clipboard: %w", err)
+		return
+	}
+	m.err = nil
+	m.status = fmt.Sprintf("Copied a summary of %d files to clipboard", n)
+}
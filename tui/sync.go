@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"google-drive-dl/cache"
+	"google-drive-dl/drive"
+)
+
+// syncChanges returns the files that a sync run needs to download: files that
+// were modified upstream since the previous listing was taken, and any other
+// file, new ones included, that has no up-to-date local copy.
+func (m Model) syncChanges(files []drive.DriveFile, previous []cache.CachedFile) []drive.DriveFile {
+	prevByID := make(map[string]cache.CachedFile, len(previous))
+	for _, cf := range previous {
+		prevByID[cf.ID] = cf
+	}
+
+	var changed []drive.DriveFile
+	for _, f := range files {
+		prev, known := prevByID[f.ID]
+		switch {
+		case known && f.ModifiedTime.After(prev.ModifiedTime):
+			changed = append(changed, f)
+		default:
+			path, err := m.driveClient.DestPath(m.outputDir(), f)
+			if err != nil || !drive.IsLocalCopyCurrent(path, f, drive.ExistNewer) {
+				changed = append(changed, f)
+			}
+		}
+	}
+	return changed
+}
+
+// deletedUpstream returns the files in the previous listing that are no longer in files
+func deletedUpstream(files []drive.DriveFile, previous []cache.CachedFile) []cache.CachedFile {
+	current := make(map[string]bool, len(files))
+	for _, f := range files {
+		current[f.ID] = true
+	}
+
+	var deleted []cache.CachedFile
+	for _, cf := range previous {
+		if !current[cf.ID] {
+			deleted = append(deleted, cf)
+		}
+	}
+	return deleted
+}
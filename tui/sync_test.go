@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"google-drive-dl/cache"
+	"google-drive-dl/drive"
+)
+
+func TestSyncChanges(t *testing.T) {
+	dir := t.TempDir()
+	then := time.Now().Add(-time.Hour)
+	files := []drive.DriveFile{
+		{ID: "kept", Name: "kept.txt", Size: 2, ModifiedTime: then},
+		{ID: "edited", Name: "edited.txt", Size: 2, ModifiedTime: time.Now()},
+		{ID: "missing", Name: "missing.txt", Size: 2, ModifiedTime: then},
+		{ID: "new", Name: "new.txt", Size: 2, ModifiedTime: then},
+		{ID: "new-saved", Name: "new-saved.txt", Size: 2, ModifiedTime: then},
+	}
+	for _, name := range []string{"kept.txt", "edited.txt", "new-saved.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("ok"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	previous := []cache.CachedFile{
+		{ID: "kept", ModifiedTime: then},
+		{ID: "edited", ModifiedTime: then},
+		{ID: "missing", ModifiedTime: then},
+	}
+
+	m := Model{destDir: dir}
+	var got []string
+	for _, f := range m.syncChanges(files, previous) {
+		got = append(got, f.ID)
+	}
+	// A new file already saved by an earlier, unsynced run is left alone
+	if want := []string{"edited", "missing", "new"}; !slices.Equal(got, want) {
+		t.Errorf("syncChanges() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// quotaErrorWindow is how far back quota errors count towards throttling
+	quotaErrorWindow = 30 * time.Second
+	// quotaErrorThreshold is how many quota errors within quotaErrorWindow
+	// halve the number of active downloads
+	quotaErrorThreshold = 3
+	// throttleCooldown is how long the pool must go without a quota error
+	// before it lets one more download run again
+	throttleCooldown = time.Minute
+	// maxLiveConcurrency is how far + can raise concurrent downloads during
+	// a batch, unless it started higher
+	maxLiveConcurrency = 32
+)
+
+// adaptivePool limits how many downloads run at once, shrinking the limit when
+// Drive keeps answering with quota errors and growing it back after a quiet
+// cool-down. This keeps every worker from retrying into the same quota wall.
+type adaptivePool struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	ctx        context.Context
+	max        int
+	ceiling    int // the most max can be raised to; one worker runs per slot
+	limit      int
+	active     int
+	errors     []time.Time // recent quota errors, oldest first
+	lastChange time.Time   // when limit last moved
+	lastError  time.Time
+	now        func() time.Time // the clock, replaced in tests
+}
+
+// newAdaptivePool returns a pool allowing up to n active downloads. Waiters
+// are released when ctx is cancelled.
+func newAdaptivePool(ctx context.Context, n int) *adaptivePool {
+	p := &adaptivePool{ctx: ctx, max: max(n, 1), ceiling: max(n, maxLiveConcurrency), limit: max(n, 1), now: time.Now}
+	p.cond = sync.NewCond(&p.mu)
+	context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		p.cond.Broadcast()
+		p.mu.Unlock()
+	})
+	return p
+}
+
+// acquire blocks until a download may start. It returns false if the pool's
+// context was cancelled first.
+func (p *adaptivePool) acquire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if p.ctx.Err() != nil {
+			return false
+		}
+		p.raiseLimit(p.now())
+		if p.active < p.limit {
+			p.active++
+			return true
+		}
+		p.cond.Wait()
+	}
+}
+
+// release marks a download as finished
+func (p *adaptivePool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active--
+	p.raiseLimit(p.now())
+	p.cond.Broadcast()
+}
+
+// quotaError records a rate-limit response and halves the limit once they
+// pile up. It is safe to call from any goroutine.
+func (p *adaptivePool) quotaError() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.lastError = now
+	p.errors = append(p.errors, now)
+	for len(p.errors) > 0 && now.Sub(p.errors[0]) > quotaErrorWindow {
+		p.errors = p.errors[1:]
+	}
+
+	if len(p.errors) >= quotaErrorThreshold && p.limit > 1 {
+		p.limit = max(p.limit/2, 1)
+		p.lastChange = now
+		p.errors = nil // the next reduction needs fresh evidence
+		slog.Warn("Drive quota errors, reducing concurrent downloads", "limit", p.limit)
+	}
+}
+
+// raiseLimit raises the limit by one after throttleCooldown without quota
+// errors. p.mu must be held.
+func (p *adaptivePool) raiseLimit(now time.Time) {
+	if p.limit >= p.max || now.Sub(p.lastChange) < throttleCooldown || now.Sub(p.lastError) < throttleCooldown {
+		return
+	}
+	p.limit++
+	p.lastChange = now
+	slog.Info("no recent quota errors, raising concurrent downloads", "limit", p.limit)
+	p.cond.Broadcast()
+}
+
+// resize sets the most downloads that may run at once to n, clamped between
+// 1 and the pool's ceiling, and returns the new maximum. Any quota throttling
+// moves along with it. Lowering it doesn't stop running downloads; new ones
+// are held back until fewer than the new limit are active.
+func (p *adaptivePool) resize(n int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n = min(max(n, 1), p.ceiling)
+	p.limit = min(max(p.limit+n-p.max, 1), n)
+	p.max = n
+	p.cond.Broadcast()
+	return n
+}
+
+// throttled returns the current limit and whether it is below the maximum
+func (p *adaptivePool) throttled() (int, bool) {
+	if p == nil {
+		return 0, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limit, p.limit < p.max
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdaptivePool(t *testing.T) {
+	// step is something that happens to the pool at an offset from the start
+	type step struct {
+		at     time.Duration
+		op     string // "error", "release" (after an acquire), or "resize"
+		n      int    // for resize
+		limit  int    // expected afterwards
+		maxNow int
+	}
+	tests := []struct {
+		name  string
+		start int
+		steps []step
+	}{
+		{"errors below the threshold", 8, []step{
+			{at: 0, op: "error", limit: 8, maxNow: 8},
+			{at: time.Second, op: "error", limit: 8, maxNow: 8},
+		}},
+		{"errors spread beyond the window", 8, []step{
+			{at: 0, op: "error", limit: 8, maxNow: 8},
+			{at: 20 * time.Second, op: "error", limit: 8, maxNow: 8},
+			{at: 40 * time.Second, op: "error", limit: 8, maxNow: 8},
+		}},
+		{"halves and halves again on fresh errors", 8, []step{
+			{at: 0, op: "error", limit: 8, maxNow: 8},
+			{at: time.Second, op: "error", limit: 8, maxNow: 8},
+			{at: 2 * time.Second, op: "error", limit: 4, maxNow: 8},
+			{at: 3 * time.Second, op: "error", limit: 4, maxNow: 8},
+			{at: 4 * time.Second, op: "error", limit: 4, maxNow: 8},
+			{at: 5 * time.Second, op: "error", limit: 2, maxNow: 8},
+		}},
+		{"never below one", 1, []step{
+			{at: 0, op: "error", limit: 1, maxNow: 1},
+			{at: time.Second, op: "error", limit: 1, maxNow: 1},
+			{at: 2 * time.Second, op: "error", limit: 1, maxNow: 1},
+		}},
+		{"raises one at a time after the cool-down", 8, []step{
+			{at: 0, op: "error", limit: 8, maxNow: 8},
+			{at: 0, op: "error", limit: 8, maxNow: 8},
+			{at: 0, op: "error", limit: 4, maxNow: 8},
+			{at: 30 * time.Second, op: "release", limit: 4, maxNow: 8},
+			{at: 61 * time.Second, op: "release", limit: 5, maxNow: 8},
+			{at: 62 * time.Second, op: "release", limit: 5, maxNow: 8},
+			{at: 122 * time.Second, op: "release", limit: 6, maxNow: 8},
+		}},
+		{"resize moves the throttled limit along", 8, []step{
+			{at: 0, op: "error", limit: 8, maxNow: 8},
+			{at: 0, op: "error", limit: 8, maxNow: 8},
+			{at: 0, op: "error", limit: 4, maxNow: 8},
+			{at: time.Second, op: "resize", n: 10, limit: 6, maxNow: 10},
+			{at: time.Second, op: "resize", n: 2, limit: 1, maxNow: 2},
+		}},
+		{"resize is clamped", 4, []step{
+			{at: 0, op: "resize", n: 0, limit: 1, maxNow: 1},
+			{at: 0, op: "resize", n: 100, limit: maxLiveConcurrency, maxNow: maxLiveConcurrency},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			now := start
+			p := newAdaptivePool(context.Background(), tt.start)
+			p.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = start.Add(s.at)
+				switch s.op {
+				case "error":
+					p.quotaError()
+				case "release":
+					if !p.acquire() {
+						t.Fatalf("step %d: acquire failed", i)
+					}
+					p.release()
+				case "resize":
+					if got := p.resize(s.n); got != s.maxNow {
+						t.Errorf("step %d: resize(%d) = %d, want %d", i, s.n, got, s.maxNow)
+					}
+				}
+				if limit, _ := p.throttled(); limit != s.limit || p.max != s.maxNow {
+					t.Errorf("step %d (%s at %v): limit %d of %d, want %d of %d", i, s.op, s.at, limit, p.max, s.limit, s.maxNow)
+				}
+			}
+		})
+	}
+}